@@ -15,18 +15,23 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jpasei/zerohalt/pkg/config"
 	"github.com/jpasei/zerohalt/pkg/health"
+	"github.com/jpasei/zerohalt/pkg/hooks"
 	"github.com/jpasei/zerohalt/pkg/metrics"
 	"github.com/jpasei/zerohalt/pkg/monitor"
 	"github.com/jpasei/zerohalt/pkg/process"
+	"github.com/jpasei/zerohalt/pkg/proxy"
 	"github.com/jpasei/zerohalt/pkg/shutdown"
 )
 
@@ -68,10 +73,12 @@ func (c *ConfigAdapter) GetSignalConfig() process.SignalConfig {
 	return process.SignalConfig{
 		PassThroughSignals: c.Signal.PassThroughSignals,
 		ShutdownSignals:    c.Signal.ShutdownSignals,
+		RestartSignal:      c.Signal.RestartSignal,
+		CancelDrainSignal:  c.Signal.CancelDrainSignal,
 	}
 }
 
-func (c *ConfigAdapter) GetConnectionCheckInterval() interface{} {
+func (c *ConfigAdapter) GetConnectionCheckInterval() time.Duration {
 	return c.Shutdown.ConnectionCheckInterval
 }
 
@@ -87,11 +94,11 @@ type ShutdownConfigAdapter struct {
 	*config.ShutdownConfig
 }
 
-func (s *ShutdownConfigAdapter) GetDrainTimeout() interface{} {
+func (s *ShutdownConfigAdapter) GetDrainTimeout() time.Duration {
 	return s.DrainTimeout
 }
 
-func (s *ShutdownConfigAdapter) GetShutdownTimeout() interface{} {
+func (s *ShutdownConfigAdapter) GetShutdownTimeout() time.Duration {
 	return s.ShutdownTimeout
 }
 
@@ -103,31 +110,131 @@ func (s *ShutdownConfigAdapter) GetForceKillAfterTimeout() bool {
 	return s.ForceKillAfterTimeout
 }
 
-type HealthServerAdapter struct {
-	*health.Server
+const (
+	adminRestartPath     = "/admin/restart"
+	adminCancelDrainPath = "/admin/cancel-drain"
+)
+
+// drainFileEnv tells the application where the drain marker file appears.
+const drainFileEnv = "ZEROHALT_DRAIN_FILE"
+
+var osExit = os.Exit
+
+type Restarter interface {
+	Restart() error
 }
 
-func (h *HealthServerAdapter) SetState(state health.HealthState) {
-	h.Server.SetState(state)
+func restartHandler(restarter Restarter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := restarter.Restart()
+
+		isConflict := errors.Is(err, process.ErrRestartInProgress) || errors.Is(err, process.ErrShuttingDown)
+		switch {
+		case isConflict:
+			slog.Warn("Restart request rejected", "error", err)
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"status":"rejected"}`))
+		case err != nil:
+			slog.Error("Application restart failed", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status":"failed"}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"status":"restarted"}`))
+		}
+	}
 }
 
-func (h *HealthServerAdapter) GetState() health.HealthState {
-	return h.Server.GetState()
+// Drainer runs the drain phase ahead of the shutdown signal.
+type Drainer interface {
+	Drain(ctx context.Context) error
 }
 
-func (h *HealthServerAdapter) WaitForAppHealthy(timeout time.Duration, interval time.Duration) bool {
-	return h.Server.WaitForAppHealthy(timeout, interval)
+// preStopHandler blocks until the drain completes, so a Kubernetes preStop
+// hook holds off SIGTERM until connections are gone.
+func preStopHandler(drainer Drainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := drainer.Drain(r.Context())
+
+		switch {
+		case r.Context().Err() != nil:
+			slog.Warn("PreStop request ended before the drain completed", "error", r.Context().Err())
+		case errors.Is(err, shutdown.ErrDrainCancelled):
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"status":"cancelled"}`))
+		case err != nil:
+			slog.Warn("PreStop drain did not complete", "error", err)
+			w.WriteHeader(http.StatusGatewayTimeout)
+			w.Write([]byte(`{"status":"timeout"}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"status":"drained"}`))
+		}
+	}
 }
 
-type MonitorAdapter struct {
-	*monitor.Monitor
+// DrainCanceller aborts a drain and returns the service to rotation.
+type DrainCanceller interface {
+	CancelDrain(source string) error
 }
 
-func (m *MonitorAdapter) WaitForZeroConnections(timeout interface{}) error {
-	return m.Monitor.WaitForZeroConnections(timeout.(time.Duration))
+func cancelDrainHandler(canceller DrainCanceller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := canceller.CancelDrain("admin")
+
+		isConflict := errors.Is(err, shutdown.ErrNoDrain) || errors.Is(err, shutdown.ErrAppSignalled)
+		switch {
+		case isConflict:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"status":"rejected"}`))
+		case err != nil:
+			slog.Error("Drain cancellation failed", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status":"failed"}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"status":"cancelled"}`))
+		}
+	}
 }
 
-var osExit = os.Exit
+// stoppingHandler takes a healthy application out of rotation as soon as it
+// reports STOPPING=1. Shutdown states are left alone.
+func stoppingHandler(healthServer *health.DrainingServer) func() {
+	return func() {
+		if healthServer.GetState() == health.StateHealthy {
+			healthServer.SetState(health.StateUnhealthy)
+		}
+	}
+}
+
+func watchdogHandler(action config.WatchdogAction, healthServer *health.DrainingServer, restarter Restarter) func(missed bool) {
+	return func(missed bool) {
+		state := healthServer.GetState()
+		isShuttingDown := state == health.StateDraining || state == health.StateTerminating
+		if isShuttingDown {
+			return
+		}
+
+		if !missed {
+			if action == config.WatchdogActionUnhealthy && state == health.StateUnhealthy {
+				healthServer.SetState(health.StateHealthy)
+			}
+			return
+		}
+
+		if action == config.WatchdogActionUnhealthy {
+			healthServer.SetState(health.StateUnhealthy)
+			return
+		}
+
+		go func() {
+			if err := restarter.Restart(); err != nil {
+				slog.Error("Watchdog restart failed", "error", err)
+			}
+		}()
+	}
+}
 
 func setupLogger(level string) {
 	var logLevel slog.Level
@@ -151,25 +258,28 @@ func setupLogger(level string) {
 	slog.SetDefault(slog.New(handler))
 }
 
-func setupMetrics(cfg *config.Config, healthServer *HealthServerAdapter) {
+// setupMetrics serves metrics on the health server, or on a separate server
+// that is returned so it can be shut down.
+func setupMetrics(cfg *config.Config, healthServer *health.DrainingServer) *http.Server {
 	metricsOnSamePort := cfg.Metrics.Port == cfg.Health.Port
 
 	if metricsOnSamePort {
 		enableMetricsOnHealthServer(cfg, healthServer)
-		return
+		return nil
 	}
 
-	startSeparateMetricsServer(cfg)
+	metricsServer := startSeparateMetricsServer(cfg)
 	startUptimeTracker()
+	return metricsServer
 }
 
-func enableMetricsOnHealthServer(cfg *config.Config, healthServer *HealthServerAdapter) {
+func enableMetricsOnHealthServer(cfg *config.Config, healthServer *health.DrainingServer) {
 	healthServer.Server.EnableMetrics(cfg.Metrics.Path)
 	slog.Info("Metrics enabled on health server", "path", cfg.Metrics.Path, "port", cfg.Health.Port)
 	startUptimeTracker()
 }
 
-func startSeparateMetricsServer(cfg *config.Config) {
+func startSeparateMetricsServer(cfg *config.Config) *http.Server {
 	mux := http.NewServeMux()
 	mux.Handle(cfg.Metrics.Path, metrics.Handler())
 
@@ -180,6 +290,7 @@ func startSeparateMetricsServer(cfg *config.Config) {
 	}
 
 	go runMetricsServer(metricsServer, cfg)
+	return metricsServer
 }
 
 func runMetricsServer(server *http.Server, cfg *config.Config) {
@@ -196,6 +307,127 @@ func runMetricsServer(server *http.Server, cfg *config.Config) {
 	}
 }
 
+// proxyServer is a running proxy. Shutdown lets in-flight traffic finish
+// before it stops.
+type proxyServer interface {
+	Shutdown(ctx context.Context) error
+}
+
+// startProxy starts the proxy configured for cfg, if any, and returns it so
+// it can be shut down on exit. Only the HTTP proxy, a *proxy.Proxy, can hold
+// requests during a restart.
+func startProxy(cfg *config.Config, connMonitor *monitor.Monitor, healthServer *health.DrainingServer) (proxyServer, error) {
+	if !cfg.Proxy.Enabled {
+		return nil, nil
+	}
+
+	if cfg.Proxy.Mode == config.ProxyModeTCP {
+		tcpProxy := proxy.NewTCPProxy(cfg.Proxy.Port, cfg.App.Port)
+		tcpProxy.SetDrainGrace(cfg.Proxy.DrainGrace, cfg.Proxy.DrainCloseMode)
+		if err := tcpProxy.Start(); err != nil {
+			return nil, err
+		}
+
+		connMonitor.SetRequestCounter(cfg.App.Port, tcpProxy)
+		healthServer.OnDraining(tcpProxy.SetDraining)
+		return tcpProxy, nil
+	}
+
+	appProxy := proxy.NewProxy(cfg.Proxy.Port, cfg.App.Port)
+	appProxy.SetHoldLimits(cfg.Proxy.HoldQueueSize, cfg.Proxy.HoldTimeout)
+	if cfg.Proxy.MaxConcurrency > 0 {
+		appProxy.SetLimiter(newLimiter(cfg))
+	}
+	if err := appProxy.Start(); err != nil {
+		return nil, err
+	}
+
+	connMonitor.SetRequestCounter(cfg.App.Port, appProxy)
+	healthServer.OnDraining(appProxy.SetDraining)
+	healthServer.OnResume(appProxy.ResumeFromDraining)
+	return appProxy, nil
+}
+
+// activationNames returns LISTEN_FDNAMES entries for ports, defaulting to
+// the port numbers.
+func activationNames(cfg *config.Config, ports []uint16) []string {
+	if len(cfg.Activation.Names) > 0 {
+		return cfg.Activation.Names
+	}
+
+	names := make([]string, 0, len(ports))
+	for _, port := range ports {
+		names = append(names, strconv.Itoa(int(port)))
+	}
+	return names
+}
+
+// newHooks names each hook after its phase and position, e.g. pre-start-1,
+// which also prefixes its output.
+func newHooks(phase string, hookConfigs []config.HookConfig) []hooks.Hook {
+	phaseHooks := make([]hooks.Hook, 0, len(hookConfigs))
+
+	for i, hookConfig := range hookConfigs {
+		phaseHooks = append(phaseHooks, hooks.Hook{
+			Name:    fmt.Sprintf("%s-%d", phase, i+1),
+			Command: hookConfig.Command,
+			Method:  hookConfig.Method,
+			URL:     hookConfig.URL,
+			Timeout: hookConfig.Timeout,
+			Retries: hookConfig.Retries,
+		})
+	}
+
+	return phaseHooks
+}
+
+func newLimiter(cfg *config.Config) *proxy.Limiter {
+	limiter := proxy.NewLimiter(cfg.Proxy.MaxConcurrency, cfg.Proxy.QueueSize, cfg.Proxy.QueueTimeout)
+
+	if cfg.Proxy.AdaptiveConcurrency {
+		limiter.SetAdaptive(cfg.Proxy.MinConcurrency, cfg.Proxy.LatencyTarget)
+	}
+
+	slog.Info("Proxy concurrency limit enabled", "max_concurrency", cfg.Proxy.MaxConcurrency, "queue_size", cfg.Proxy.QueueSize, "queue_timeout", cfg.Proxy.QueueTimeout, "adaptive", cfg.Proxy.AdaptiveConcurrency)
+	return limiter
+}
+
+// stopServers reports Terminating and keeps the health and metrics servers
+// up for linger, so probes and the last Prometheus scrape see the final
+// state, then shuts both down.
+func stopServers(healthServer *health.DrainingServer, metricsServer *http.Server, appProxy proxyServer, linger time.Duration) {
+	healthServer.SetState(health.StateTerminating)
+	metrics.HealthApp.Set(float64(health.StateTerminating))
+
+	if appProxy != nil {
+		proxyCtx, cancelProxy := context.WithTimeout(context.Background(), health.ServerShutdownTimeout)
+		if err := appProxy.Shutdown(proxyCtx); err != nil {
+			slog.Warn("Proxy shutdown error", "error", err)
+		}
+		cancelProxy()
+	}
+
+	if linger > 0 {
+		slog.Info("Serving final health state and metrics before exit", "linger", linger)
+		time.Sleep(linger)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), health.ServerShutdownTimeout)
+	defer cancel()
+
+	if err := healthServer.Shutdown(ctx); err != nil {
+		slog.Warn("Health server shutdown error", "error", err)
+	}
+
+	if metricsServer == nil {
+		return
+	}
+
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		slog.Warn("Metrics server shutdown error", "error", err)
+	}
+}
+
 func startUptimeTracker() {
 	go func() {
 		ticker := time.NewTicker(1 * time.Second)
@@ -222,6 +454,10 @@ func run(args []string) int {
 
 	setupLogger(cfg.Logging.Level)
 
+	for _, warning := range cfg.Warnings() {
+		slog.Warn("Configuration warning", "warning", warning)
+	}
+
 	slog.Info("Starting Zerohalt", "version", Version)
 	slog.Debug("Configuration loaded", "app_port", cfg.App.Port, "health_port", cfg.Health.Port)
 
@@ -235,50 +471,151 @@ func run(args []string) int {
 
 	slog.Info("Application command", "command", cfg.App.Command)
 
-	var healthServer *HealthServerAdapter
+	var healthServer *health.DrainingServer
+	var notifySocket *health.NotifySocket
 	if cfg.Health.Mode == config.HealthModeAppDependent {
 		appChecker := health.NewAppHealthChecker(cfg.App.HealthURL, cfg.Health.ProbeTimeout)
-		healthServer = &HealthServerAdapter{
+		healthServer = &health.DrainingServer{
 			Server: health.NewServerWithAppChecker(cfg.Health.Port, cfg.Health.Path, appChecker),
 		}
 		slog.Info("Health server created in app-dependent mode", "app_health_url", cfg.App.HealthURL)
+	} else if cfg.Health.Mode == config.HealthModeNotify {
+		var err error
+		notifySocket, err = health.NewNotifySocket(cfg.Health.Watchdog)
+		if err != nil {
+			slog.Error("Failed to create notify socket", "error", err)
+			return 1
+		}
+		defer notifySocket.Close()
+
+		healthServer = &health.DrainingServer{
+			Server: health.NewServerWithNotify(cfg.Health.Port, cfg.Health.Path, notifySocket),
+		}
+		slog.Info("Health server created in notify mode", "notify_socket", notifySocket.Path(), "watchdog", cfg.Health.Watchdog, "watchdog_action", cfg.Health.WatchdogAction)
 	} else {
-		healthServer = &HealthServerAdapter{
+		healthServer = &health.DrainingServer{
 			Server: health.NewServer(cfg.Health.Port, cfg.Health.Path),
 		}
 		slog.Info("Health server created in standalone mode")
 	}
 
+	var metricsServer *http.Server
 	if cfg.Metrics.Enabled {
-		setupMetrics(cfg, healthServer)
+		metricsServer = setupMetrics(cfg, healthServer)
 	}
 
 	ports := []uint16{cfg.App.Port}
 	ports = append(ports, cfg.App.AdditionalPorts...)
 
-	connMonitor := &MonitorAdapter{
-		Monitor: monitor.NewMonitor(ports, cfg.Shutdown.ConnectionCheckInterval),
+	connMonitor := monitor.NewMonitor(ports, cfg.Shutdown.ConnectionCheckInterval)
+	connMonitor.SetSteadyStateWait(cfg.Shutdown.DrainSteadyStateWait)
+	connMonitor.SetIgnoredPorts([]uint16{cfg.Health.Port, cfg.Metrics.Port, cfg.Proxy.Port})
+
+	remoteFilter, err := monitor.NewRemoteFilter(cfg.Monitor.IncludeCIDRs, cfg.Monitor.ExcludeCIDRs, cfg.Monitor.ExcludeLoopback)
+	if err != nil {
+		slog.Error("Invalid connection filter", "error", err)
+		return 1
+	}
+	connMonitor.SetRemoteFilter(remoteFilter)
+	connMonitor.SetProcessTreeOnly(cfg.Monitor.ProcessTreeOnly)
+	connMonitor.SetUnixSocketPaths(cfg.Monitor.UnixSocketPaths)
+	connMonitor.SetUDPMonitoring(cfg.Monitor.UDPPorts, cfg.Monitor.UDPSignal, cfg.Monitor.UDPIdleTimeout)
+	if cfg.Monitor.DiscoverPorts {
+		connMonitor.SetPortDiscovery(cfg.Monitor.DiscoveryInterval)
+		slog.Info("Listening port discovery enabled", "interval", cfg.Monitor.DiscoveryInterval)
 	}
-	connMonitor.Monitor.SetSteadyStateWait(cfg.Shutdown.DrainSteadyStateWait)
-	connMonitor.Monitor.Start()
-	slog.Info("Connection monitoring started", "ports", ports, "interval", cfg.Shutdown.ConnectionCheckInterval, "steady_state_wait", cfg.Shutdown.DrainSteadyStateWait)
+	if cfg.Shutdown.ForceCloseConnections {
+		connMonitor.SetForceClose(cfg.Shutdown.ForceCloseAfter, cfg.Shutdown.MaxConnectionAge)
+		slog.Info("Connection force close enabled", "after", cfg.Shutdown.ForceCloseAfter, "max_connection_age", cfg.Shutdown.MaxConnectionAge)
+	}
+	proxyServer, err := startProxy(cfg, connMonitor, healthServer)
+	if err != nil {
+		slog.Error("Failed to start proxy", "error", err)
+		return 1
+	}
+	appProxy, _ := proxyServer.(*proxy.Proxy)
+	connMonitor.Start()
+	slog.Info("Connection monitoring started", "ports", ports, "unix_sockets", cfg.Monitor.UnixSocketPaths, "udp_ports", cfg.Monitor.UDPPorts, "udp_signal", cfg.Monitor.UDPSignal, "interval", cfg.Shutdown.ConnectionCheckInterval, "steady_state_wait", cfg.Shutdown.DrainSteadyStateWait, "include_cidrs", cfg.Monitor.IncludeCIDRs, "exclude_cidrs", cfg.Monitor.ExcludeCIDRs, "exclude_loopback", cfg.Monitor.ExcludeLoopback, "process_tree_only", cfg.Monitor.ProcessTreeOnly)
 
 	configAdapter := &ConfigAdapter{Config: cfg}
 	manager := process.NewManager(configAdapter)
 
+	if cfg.Activation.Enabled {
+		files, err := process.OpenListeners(ports)
+		if err != nil {
+			slog.Error("Failed to open listeners for socket activation", "error", err)
+			return 1
+		}
+
+		names := activationNames(cfg, ports)
+		manager.SetInheritedListeners(files, names)
+		slog.Info("Socket activation enabled", "ports", ports, "names", names)
+	}
+
+	manager.SetPreStartHooks(newHooks("pre-start", cfg.Hooks.PreStart))
+
+	if path := cfg.Shutdown.DrainNotifyFile; path != "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			slog.Error("Failed to remove stale drain notify file", "path", path, "error", err)
+			return 1
+		}
+		manager.SetEnvironment([]string{drainFileEnv + "=" + path})
+	}
+
+	if notifySocket != nil {
+		manager.SetNotifySocket(notifySocket)
+		notifySocket.OnStopping(stoppingHandler(healthServer))
+		notifySocket.OnWatchdog(watchdogHandler(cfg.Health.WatchdogAction, healthServer, manager))
+	}
+
+	if appProxy != nil {
+		manager.SetRequestGate(appProxy)
+
+		if cfg.Admin.Token != "" {
+			healthServer.Server.EnableAdminEndpoint(adminRestartPath, cfg.Admin.Token, restartHandler(manager))
+		}
+	}
+
 	shutdownCoord := shutdown.NewCoordinator(
 		&shutdown.ShutdownConfig{
 			DrainTimeout:          cfg.Shutdown.DrainTimeout,
+			DrainDelay:            cfg.Shutdown.DrainDelay,
 			ShutdownTimeout:       cfg.Shutdown.ShutdownTimeout,
 			SignalToApp:           cfg.Shutdown.SignalToApp,
 			ForceKillAfterTimeout: cfg.Shutdown.ForceKillAfterTimeout,
+			RepeatSignalAction:    cfg.Shutdown.RepeatSignalAction,
+			Budget:                cfg.ShutdownBudget(),
+			PreDrainHooks:         newHooks("pre-drain", cfg.Hooks.PreDrain),
+			PreStopHooks:          newHooks("pre-stop", cfg.Hooks.PreStop),
+			PostStopHooks:         newHooks("post-stop", cfg.Hooks.PostStop),
+			DrainNotify: shutdown.DrainNotifyConfig{
+				Signal:   process.ParseSignal(cfg.Shutdown.DrainNotifySignal),
+				URL:      cfg.Shutdown.DrainNotifyURL,
+				FilePath: cfg.Shutdown.DrainNotifyFile,
+			},
+			Timeline: shutdown.TimelineConfig{
+				Enabled:            cfg.Shutdown.Timeline,
+				TerminationLogPath: cfg.Shutdown.TerminationLogPath,
+			},
 		},
 		healthServer,
 		connMonitor,
 		nil,
 	)
 
-	if err := manager.Run(healthServer, connMonitor, shutdownCoord); err != nil {
+	if path := cfg.Health.PreStopPath; path != "" {
+		healthServer.Server.EnablePreStopEndpoint(path, cfg.Admin.Token, preStopHandler(shutdownCoord))
+	}
+
+	usesTCPProxy := cfg.Proxy.Enabled && cfg.Proxy.Mode == config.ProxyModeTCP
+	if cfg.Admin.Token != "" && !usesTCPProxy {
+		healthServer.Server.EnableAdminEndpoint(adminCancelDrainPath, cfg.Admin.Token, cancelDrainHandler(shutdownCoord))
+	}
+
+	err = manager.Run(healthServer, connMonitor, shutdownCoord)
+	stopServers(healthServer, metricsServer, proxyServer, cfg.Shutdown.Linger)
+
+	if err != nil {
 		slog.Error("Manager error", "error", err)
 		return 1
 	}
@@ -15,15 +15,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/jpasei/zerohalt/pkg/config"
 	"github.com/jpasei/zerohalt/pkg/health"
+	"github.com/jpasei/zerohalt/pkg/hooks"
 	"github.com/jpasei/zerohalt/pkg/monitor"
+	"github.com/jpasei/zerohalt/pkg/process"
+	"github.com/jpasei/zerohalt/pkg/proxy"
+	"github.com/jpasei/zerohalt/pkg/shutdown"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -109,7 +116,7 @@ func TestConfigAdapter_GetConnectionCheckInterval(t *testing.T) {
 	adapter := &ConfigAdapter{Config: cfg}
 	interval := adapter.GetConnectionCheckInterval()
 
-	assert.Equal(t, 1*time.Second, interval.(time.Duration))
+	assert.Equal(t, 1*time.Second, interval)
 }
 
 func TestShutdownConfigAdapter_GetDrainTimeout(t *testing.T) {
@@ -120,7 +127,7 @@ func TestShutdownConfigAdapter_GetDrainTimeout(t *testing.T) {
 	adapter := &ShutdownConfigAdapter{ShutdownConfig: cfg}
 	timeout := adapter.GetDrainTimeout()
 
-	assert.Equal(t, 60*time.Second, timeout.(time.Duration))
+	assert.Equal(t, 60*time.Second, timeout)
 }
 
 func TestShutdownConfigAdapter_GetShutdownTimeout(t *testing.T) {
@@ -131,7 +138,7 @@ func TestShutdownConfigAdapter_GetShutdownTimeout(t *testing.T) {
 	adapter := &ShutdownConfigAdapter{ShutdownConfig: cfg}
 	timeout := adapter.GetShutdownTimeout()
 
-	assert.Equal(t, 30*time.Second, timeout.(time.Duration))
+	assert.Equal(t, 30*time.Second, timeout)
 }
 
 func TestShutdownConfigAdapter_GetSignalToApp(t *testing.T) {
@@ -183,40 +190,279 @@ func TestConfigAdapter_GetShutdownConfig(t *testing.T) {
 	assert.NotNil(t, shutdownCfg)
 
 	drainTimeout := shutdownCfg.GetDrainTimeout()
-	assert.Equal(t, 60*time.Second, drainTimeout.(time.Duration))
+	assert.Equal(t, 60*time.Second, drainTimeout)
 }
 
-func TestHealthServerAdapter_SetState(t *testing.T) {
-	port := getAvailablePort()
-	server := &HealthServerAdapter{
-		Server: health.NewServer(port, "/health"),
+type mockRestarter struct {
+	err    error
+	called chan struct{}
+}
+
+func (m *mockRestarter) Restart() error {
+	if m.called != nil {
+		close(m.called)
 	}
+	return m.err
+}
 
-	server.SetState(health.StateHealthy)
-	state := server.GetState()
+func TestRestartHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"success", nil, http.StatusOK, `{"status":"restarted"}`},
+		{"in progress", process.ErrRestartInProgress, http.StatusConflict, `{"status":"rejected"}`},
+		{"shutting down", process.ErrShuttingDown, http.StatusConflict, `{"status":"rejected"}`},
+		{"unhealthy", process.ErrRestartUnhealthy, http.StatusInternalServerError, `{"status":"failed"}`},
+	}
 
-	assert.Equal(t, health.StateHealthy, state)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := restartHandler(&mockRestarter{err: tt.err})
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest("POST", adminRestartPath, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
 }
 
-func TestHealthServerAdapter_GetState(t *testing.T) {
-	port := getAvailablePort()
-	server := &HealthServerAdapter{
-		Server: health.NewServer(port, "/health"),
+type mockDrainer struct {
+	err error
+}
+
+func (m *mockDrainer) Drain(ctx context.Context) error {
+	return m.err
+}
+
+func TestPreStopHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"drained", nil, http.StatusOK, `{"status":"drained"}`},
+		{"timeout", monitor.ErrDrainTimeout, http.StatusGatewayTimeout, `{"status":"timeout"}`},
+		{"cancelled", shutdown.ErrDrainCancelled, http.StatusConflict, `{"status":"cancelled"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := preStopHandler(&mockDrainer{err: tt.err})
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest("GET", "/prestop", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+type mockDrainCanceller struct {
+	err    error
+	source string
+}
+
+func (m *mockDrainCanceller) CancelDrain(source string) error {
+	m.source = source
+	return m.err
+}
+
+func TestCancelDrainHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"cancelled", nil, http.StatusOK, `{"status":"cancelled"}`},
+		{"no drain", shutdown.ErrNoDrain, http.StatusConflict, `{"status":"rejected"}`},
+		{"app signalled", shutdown.ErrAppSignalled, http.StatusConflict, `{"status":"rejected"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canceller := &mockDrainCanceller{err: tt.err}
+			handler := cancelDrainHandler(canceller)
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest("POST", adminCancelDrainPath, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+			assert.Equal(t, "admin", canceller.source)
+		})
+	}
+}
+
+func TestStoppingHandler(t *testing.T) {
+	healthServer := &health.DrainingServer{Server: health.NewServer(getAvailablePort(), "/health")}
+	handler := stoppingHandler(healthServer)
+
+	healthServer.SetState(health.StateHealthy)
+	handler()
+	assert.Equal(t, health.StateUnhealthy, healthServer.GetState())
+
+	healthServer.SetState(health.StateDraining)
+	handler()
+	assert.Equal(t, health.StateDraining, healthServer.GetState())
+}
+
+func TestWatchdogHandler_Unhealthy(t *testing.T) {
+	healthServer := &health.DrainingServer{Server: health.NewServer(getAvailablePort(), "/health")}
+	healthServer.SetState(health.StateHealthy)
+	handler := watchdogHandler(config.WatchdogActionUnhealthy, healthServer, &mockRestarter{})
+
+	handler(true)
+	assert.Equal(t, health.StateUnhealthy, healthServer.GetState())
+
+	handler(false)
+	assert.Equal(t, health.StateHealthy, healthServer.GetState(), "resumed keep-alives should restore health")
+}
+
+func TestWatchdogHandler_Restart(t *testing.T) {
+	healthServer := &health.DrainingServer{Server: health.NewServer(getAvailablePort(), "/health")}
+	healthServer.SetState(health.StateHealthy)
+	restarter := &mockRestarter{called: make(chan struct{})}
+	handler := watchdogHandler(config.WatchdogActionRestart, healthServer, restarter)
+
+	handler(true)
+
+	select {
+	case <-restarter.called:
+	case <-time.After(time.Second):
+		t.Fatal("missed watchdog should restart the application")
+	}
+	assert.Equal(t, health.StateHealthy, healthServer.GetState())
+}
+
+func TestWatchdogHandler_IgnoredWhileDraining(t *testing.T) {
+	healthServer := &health.DrainingServer{Server: health.NewServer(getAvailablePort(), "/health")}
+	healthServer.SetState(health.StateDraining)
+	restarter := &mockRestarter{called: make(chan struct{})}
+	handler := watchdogHandler(config.WatchdogActionRestart, healthServer, restarter)
+
+	handler(true)
+
+	select {
+	case <-restarter.called:
+		t.Fatal("watchdog should not restart the application during shutdown")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestStartProxy_Disabled(t *testing.T) {
+	cfg := config.DefaultConfig()
+	connMonitor := monitor.NewMonitor([]uint16{8080}, time.Second)
+	healthServer := &health.DrainingServer{Server: health.NewServer(getAvailablePort(), "/health")}
+
+	appProxy, err := startProxy(cfg, connMonitor, healthServer)
+
+	assert.NoError(t, err)
+	assert.Nil(t, appProxy)
+}
+
+func TestStartProxy_Modes(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         config.ProxyMode
+		wantHTTPGate bool
+	}{
+		{"http", config.ProxyModeHTTP, true},
+		{"tcp", config.ProxyModeTCP, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Proxy.Enabled = true
+			cfg.Proxy.Mode = tt.mode
+			cfg.Proxy.Port = getAvailablePort()
+			cfg.Proxy.MaxConcurrency = 4
+
+			connMonitor := monitor.NewMonitor([]uint16{cfg.App.Port}, time.Second)
+			healthServer := &health.DrainingServer{Server: health.NewServer(getAvailablePort(), "/health")}
+
+			server, err := startProxy(cfg, connMonitor, healthServer)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer server.Shutdown(context.Background())
+
+			appProxy, isHTTP := server.(*proxy.Proxy)
+			assert.Equal(t, tt.wantHTTPGate, isHTTP)
+
+			healthServer.SetState(health.StateDraining)
+
+			if appProxy != nil {
+				w := httptest.NewRecorder()
+				appProxy.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+				assert.Equal(t, "close", w.Header().Get("Connection"), "the proxy follows the drain")
+				return
+			}
+
+			_, dialErr := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", cfg.Proxy.Port), time.Second)
+			assert.Error(t, dialErr, "the TCP proxy stops accepting once draining")
+		})
 	}
+}
+
+func TestStartProxy_PortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	assert.NoError(t, err)
+	defer listener.Close()
 
-	state := server.GetState()
+	cfg := config.DefaultConfig()
+	cfg.Proxy.Enabled = true
+	cfg.Proxy.Port = uint16(listener.Addr().(*net.TCPAddr).Port)
 
-	assert.GreaterOrEqual(t, state, health.StateStarting)
+	connMonitor := monitor.NewMonitor([]uint16{8080}, time.Second)
+	healthServer := &health.DrainingServer{Server: health.NewServer(getAvailablePort(), "/health")}
+
+	_, err = startProxy(cfg, connMonitor, healthServer)
+	assert.Error(t, err)
 }
 
-func TestMonitorAdapter_WaitForZeroConnections(t *testing.T) {
-	mon := &MonitorAdapter{
-		Monitor: monitor.NewMonitor([]uint16{8080}, 100*time.Millisecond),
+func TestActivationNames(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ports := []uint16{8080, 9090}
+
+	assert.Equal(t, []string{"8080", "9090"}, activationNames(cfg, ports))
+
+	cfg.Activation.Names = []string{"http", "grpc"}
+	assert.Equal(t, []string{"http", "grpc"}, activationNames(cfg, ports))
+}
+
+func TestNewHooks(t *testing.T) {
+	hookConfigs := []config.HookConfig{
+		{Command: []string{"/app/migrate"}, Timeout: time.Minute, Retries: 2},
+		{Method: "PUT", URL: "http://config/fetch", Timeout: time.Second},
 	}
 
-	err := mon.WaitForZeroConnections(1 * time.Millisecond)
+	expected := []hooks.Hook{
+		{Name: "pre-start-1", Command: []string{"/app/migrate"}, Timeout: time.Minute, Retries: 2},
+		{Name: "pre-start-2", Method: "PUT", URL: "http://config/fetch", Timeout: time.Second},
+	}
+	assert.Equal(t, expected, newHooks("pre-start", hookConfigs))
+	assert.Empty(t, newHooks("pre-start", nil))
+}
+
+func TestNewLimiter(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Proxy.MaxConcurrency = 8
+	cfg.Proxy.AdaptiveConcurrency = true
+	cfg.Proxy.MinConcurrency = 2
 
-	t.Logf("WaitForZeroConnections() error = %v (expected for test)", err)
+	limiter := newLimiter(cfg)
+
+	assert.NotNil(t, limiter)
+	assert.Equal(t, 8, limiter.Limit())
 }
 
 func TestRun_Version(t *testing.T) {
@@ -605,3 +851,81 @@ func TestRunMetricsServer_ListenError(t *testing.T) {
 		t.Fatal("Test timed out waiting for server error")
 	}
 }
+
+func TestStopServers_LingersThenShutsDown(t *testing.T) {
+	healthPort := getAvailablePort()
+	metricsPort := getAvailablePort()
+
+	healthServer := &health.DrainingServer{Server: health.NewServer(healthPort, "/health")}
+	healthServer.Start()
+	healthServer.SetState(health.StateHealthy)
+
+	metricsServer := startSeparateMetricsServer(&config.Config{
+		Metrics: config.MetricsConfig{Port: metricsPort, Path: "/metrics"},
+	})
+	time.Sleep(100 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		stopServers(healthServer, metricsServer, nil, 500*time.Millisecond)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/health", healthPort))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp.Body.Close()
+	assert.Equal(t, health.StateTerminating, healthServer.GetState())
+
+	resp, err = http.Get(fmt.Sprintf("http://localhost:%d/metrics", metricsPort))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "final metrics should still be scrapeable while lingering")
+	resp.Body.Close()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("stopServers should return after the linger period")
+	}
+
+	_, err = http.Get(fmt.Sprintf("http://localhost:%d/health", healthPort))
+	assert.Error(t, err)
+	_, err = http.Get(fmt.Sprintf("http://localhost:%d/metrics", metricsPort))
+	assert.Error(t, err)
+}
+
+func TestStopServers_WithoutMetricsServer(t *testing.T) {
+	healthPort := getAvailablePort()
+	healthServer := &health.DrainingServer{Server: health.NewServer(healthPort, "/health")}
+	healthServer.Start()
+	time.Sleep(50 * time.Millisecond)
+
+	stopServers(healthServer, nil, nil, 0)
+
+	_, err := http.Get(fmt.Sprintf("http://localhost:%d/health", healthPort))
+	assert.Error(t, err)
+}
+
+type mockProxyServer struct {
+	shutdown chan struct{}
+}
+
+func (m *mockProxyServer) Shutdown(ctx context.Context) error {
+	close(m.shutdown)
+	return nil
+}
+
+func TestStopServers_ShutsDownProxy(t *testing.T) {
+	healthServer := &health.DrainingServer{Server: health.NewServer(getAvailablePort(), "/health")}
+	appProxy := &mockProxyServer{shutdown: make(chan struct{})}
+
+	stopServers(healthServer, nil, appProxy, 0)
+
+	select {
+	case <-appProxy.shutdown:
+	default:
+		t.Fatal("stopServers should shut down the proxy")
+	}
+}
@@ -16,15 +16,24 @@ package config
 
 import (
 	"time"
+
+	"github.com/jpasei/zerohalt/pkg/monitor"
+	"github.com/jpasei/zerohalt/pkg/proxy"
+	"github.com/jpasei/zerohalt/pkg/shutdown"
 )
 
 type Config struct {
-	App      AppConfig
-	Health   HealthConfig
-	Shutdown ShutdownConfig
-	Logging  LoggingConfig
-	Signal   SignalConfig
-	Metrics  MetricsConfig
+	App        AppConfig
+	Health     HealthConfig
+	Shutdown   ShutdownConfig
+	Logging    LoggingConfig
+	Signal     SignalConfig
+	Metrics    MetricsConfig
+	Monitor    MonitorConfig
+	Proxy      ProxyConfig
+	Admin      AdminConfig
+	Activation ActivationConfig
+	Hooks      HooksConfig
 }
 
 type AppConfig struct {
@@ -43,6 +52,9 @@ type HealthConfig struct {
 	ProbeTimeout   time.Duration
 	Command        []string
 	CommandTimeout time.Duration
+	Watchdog       time.Duration
+	WatchdogAction WatchdogAction
+	PreStopPath    string
 }
 
 type HealthMode string
@@ -52,10 +64,21 @@ const (
 	HealthModeAppDependent HealthMode = "app-dependent"
 	HealthModeHybrid       HealthMode = "hybrid"
 	HealthModeCommand      HealthMode = "command"
+	HealthModeNotify       HealthMode = "notify"
+)
+
+// WatchdogAction is what happens when an application in notify mode misses
+// its watchdog deadline.
+type WatchdogAction string
+
+const (
+	WatchdogActionUnhealthy WatchdogAction = "unhealthy"
+	WatchdogActionRestart   WatchdogAction = "restart"
 )
 
 type ShutdownConfig struct {
 	DrainTimeout            time.Duration
+	DrainDelay              time.Duration
 	DrainSteadyStateWait    time.Duration
 	ShutdownTimeout         time.Duration
 	ConnectionCheckInterval time.Duration
@@ -64,6 +87,84 @@ type ShutdownConfig struct {
 	DrainStrategy           string
 	ConnectionIdleThreshold time.Duration
 	MaxConnectionAge        time.Duration
+	ForceCloseConnections   bool
+	ForceCloseAfter         time.Duration
+	DrainNotifySignal       string
+	DrainNotifyURL          string
+	DrainNotifyFile         string
+	RepeatSignalAction      shutdown.RepeatSignalAction
+	Budget                  time.Duration
+	DrainBudgetPercent      int
+	DrainBudgetMin          time.Duration
+	StopBudgetMin           time.Duration
+	Timeline                bool
+	TerminationLogPath      string
+	Linger                  time.Duration
+}
+
+type MonitorConfig struct {
+	IncludeCIDRs      []string
+	ExcludeCIDRs      []string
+	ExcludeLoopback   bool
+	ProcessTreeOnly   bool
+	DiscoverPorts     bool
+	DiscoveryInterval time.Duration
+	UnixSocketPaths   []string
+	UDPPorts          []uint16
+	UDPSignal         monitor.UDPSignal
+	UDPIdleTimeout    time.Duration
+}
+
+type ProxyConfig struct {
+	Enabled             bool
+	Mode                ProxyMode
+	Port                uint16
+	DrainGrace          time.Duration
+	DrainCloseMode      proxy.CloseMode
+	HoldQueueSize       int
+	HoldTimeout         time.Duration
+	MaxConcurrency      int
+	QueueSize           int
+	QueueTimeout        time.Duration
+	AdaptiveConcurrency bool
+	MinConcurrency      int
+	LatencyTarget       time.Duration
+}
+
+type ProxyMode string
+
+const (
+	ProxyModeHTTP ProxyMode = "http"
+	ProxyModeTCP  ProxyMode = "tcp"
+)
+
+type AdminConfig struct {
+	Token string
+}
+
+type ActivationConfig struct {
+	Enabled bool
+	Names   []string
+}
+
+// HooksConfig holds lifecycle hooks. Timeout and Retries are the defaults
+// for hooks that do not set their own.
+type HooksConfig struct {
+	Timeout  time.Duration
+	Retries  int
+	PreStart []HookConfig
+	PreDrain []HookConfig
+	PreStop  []HookConfig
+	PostStop []HookConfig
+}
+
+// HookConfig is either an exec hook (Command) or an HTTP hook (Method, URL).
+type HookConfig struct {
+	Command []string
+	Method  string
+	URL     string
+	Timeout time.Duration
+	Retries int
 }
 
 type LoggingConfig struct {
@@ -74,6 +175,8 @@ type LoggingConfig struct {
 type SignalConfig struct {
 	PassThroughSignals []string
 	ShutdownSignals    []string
+	RestartSignal      string
+	CancelDrainSignal  string
 }
 
 type MetricsConfig struct {
@@ -97,6 +200,7 @@ func DefaultConfig() *Config {
 			ProbeTimeout:   2 * time.Second,
 			Command:        []string{},
 			CommandTimeout: 5 * time.Second,
+			WatchdogAction: WatchdogActionUnhealthy,
 		},
 		Shutdown: ShutdownConfig{
 			DrainTimeout:            60 * time.Second,
@@ -105,9 +209,15 @@ func DefaultConfig() *Config {
 			ConnectionCheckInterval: 1 * time.Second,
 			SignalToApp:             "",
 			ForceKillAfterTimeout:   true,
+			RepeatSignalAction:      shutdown.RepeatSignalIgnore,
+			DrainBudgetPercent:      80,
+			Timeline:                true,
+			TerminationLogPath:      shutdown.DefaultTerminationLogPath,
 			DrainStrategy:           "connections",
 			ConnectionIdleThreshold: 30 * time.Second,
 			MaxConnectionAge:        0,
+			ForceCloseConnections:   false,
+			ForceCloseAfter:         30 * time.Second,
 		},
 		Logging: LoggingConfig{
 			Level:            "info",
@@ -119,6 +229,48 @@ func DefaultConfig() *Config {
 			Port:    uint16(8888),
 			Path:    "/metrics",
 		},
+		Monitor: MonitorConfig{
+			IncludeCIDRs:      []string{},
+			ExcludeCIDRs:      []string{},
+			ExcludeLoopback:   false,
+			ProcessTreeOnly:   false,
+			DiscoverPorts:     false,
+			DiscoveryInterval: 10 * time.Second,
+			UnixSocketPaths:   []string{},
+			UDPPorts:          []uint16{},
+			UDPSignal:         monitor.UDPSignalQueues,
+			UDPIdleTimeout:    30 * time.Second,
+		},
+		Proxy: ProxyConfig{
+			Enabled:             false,
+			Mode:                ProxyModeHTTP,
+			Port:                0,
+			DrainGrace:          30 * time.Second,
+			DrainCloseMode:      proxy.CloseModeHalfClose,
+			HoldQueueSize:       100,
+			HoldTimeout:         30 * time.Second,
+			MaxConcurrency:      0,
+			QueueSize:           100,
+			QueueTimeout:        10 * time.Second,
+			AdaptiveConcurrency: false,
+			MinConcurrency:      1,
+			LatencyTarget:       500 * time.Millisecond,
+		},
+		Admin: AdminConfig{
+			Token: "",
+		},
+		Activation: ActivationConfig{
+			Enabled: false,
+			Names:   []string{},
+		},
+		Hooks: HooksConfig{
+			Timeout:  60 * time.Second,
+			Retries:  0,
+			PreStart: []HookConfig{},
+			PreDrain: []HookConfig{},
+			PreStop:  []HookConfig{},
+			PostStop: []HookConfig{},
+		},
 	}
 }
 
@@ -18,6 +18,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jpasei/zerohalt/pkg/monitor"
+	"github.com/jpasei/zerohalt/pkg/proxy"
+	"github.com/jpasei/zerohalt/pkg/shutdown"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -145,3 +148,114 @@ func TestHealthModeCommand(t *testing.T) {
 	got := string(HealthModeCommand)
 	assert.Equal(t, "command", got)
 }
+
+func TestDefaultConfig_ForceCloseConnections(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.False(t, cfg.Shutdown.ForceCloseConnections)
+	assert.Equal(t, 30*time.Second, cfg.Shutdown.ForceCloseAfter)
+	assert.Equal(t, time.Duration(0), cfg.Shutdown.MaxConnectionAge)
+}
+
+func TestDefaultConfig_Monitor(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Empty(t, cfg.Monitor.IncludeCIDRs)
+	assert.Empty(t, cfg.Monitor.ExcludeCIDRs)
+	assert.False(t, cfg.Monitor.ExcludeLoopback)
+	assert.False(t, cfg.Monitor.ProcessTreeOnly)
+	assert.False(t, cfg.Monitor.DiscoverPorts)
+	assert.Equal(t, 10*time.Second, cfg.Monitor.DiscoveryInterval)
+	assert.Empty(t, cfg.Monitor.UnixSocketPaths)
+}
+
+func TestDefaultConfig_UDPMonitoring(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Empty(t, cfg.Monitor.UDPPorts)
+	assert.Equal(t, monitor.UDPSignalQueues, cfg.Monitor.UDPSignal)
+	assert.Equal(t, 30*time.Second, cfg.Monitor.UDPIdleTimeout)
+}
+
+func TestDefaultConfig_Proxy(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.False(t, cfg.Proxy.Enabled)
+	assert.Equal(t, uint16(0), cfg.Proxy.Port)
+}
+
+func TestDefaultConfig_RestartHold(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, 100, cfg.Proxy.HoldQueueSize)
+	assert.Equal(t, 30*time.Second, cfg.Proxy.HoldTimeout)
+	assert.Empty(t, cfg.Admin.Token)
+	assert.Empty(t, cfg.Signal.RestartSignal)
+}
+
+func TestDefaultConfig_ProxyConcurrency(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, 0, cfg.Proxy.MaxConcurrency)
+	assert.Equal(t, 100, cfg.Proxy.QueueSize)
+	assert.Equal(t, 10*time.Second, cfg.Proxy.QueueTimeout)
+	assert.False(t, cfg.Proxy.AdaptiveConcurrency)
+	assert.Equal(t, 1, cfg.Proxy.MinConcurrency)
+	assert.Equal(t, 500*time.Millisecond, cfg.Proxy.LatencyTarget)
+}
+
+func TestDefaultConfig_TCPProxy(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, ProxyModeHTTP, cfg.Proxy.Mode)
+	assert.Equal(t, 30*time.Second, cfg.Proxy.DrainGrace)
+	assert.Equal(t, proxy.CloseModeHalfClose, cfg.Proxy.DrainCloseMode)
+}
+
+func TestDefaultConfig_Activation(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.False(t, cfg.Activation.Enabled)
+	assert.Empty(t, cfg.Activation.Names)
+}
+
+func TestDefaultConfig_Watchdog(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Zero(t, cfg.Health.Watchdog)
+	assert.Equal(t, WatchdogActionUnhealthy, cfg.Health.WatchdogAction)
+}
+
+func TestDefaultConfig_PreStartHooks(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, 60*time.Second, cfg.Hooks.Timeout)
+	assert.Equal(t, 0, cfg.Hooks.Retries)
+	assert.Empty(t, cfg.Hooks.PreStart)
+}
+
+func TestDefaultConfig_ShutdownHooks(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Empty(t, cfg.Hooks.PreDrain)
+	assert.Empty(t, cfg.Hooks.PreStop)
+	assert.Empty(t, cfg.Hooks.PostStop)
+}
+
+func TestDefaultConfig_DrainNotify(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Empty(t, cfg.Shutdown.DrainNotifySignal)
+	assert.Empty(t, cfg.Shutdown.DrainNotifyURL)
+	assert.Empty(t, cfg.Shutdown.DrainNotifyFile)
+}
+
+func TestDefaultConfig_DrainDelay(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Zero(t, cfg.Shutdown.DrainDelay)
+}
+
+func TestDefaultConfig_RepeatSignalAction(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, shutdown.RepeatSignalIgnore, cfg.Shutdown.RepeatSignalAction)
+}
+
+func TestDefaultConfig_ShutdownBudget(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Zero(t, cfg.Shutdown.Budget)
+	assert.Equal(t, 80, cfg.Shutdown.DrainBudgetPercent)
+}
+
+func TestDefaultConfig_ShutdownTimeline(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.True(t, cfg.Shutdown.Timeline)
+	assert.Equal(t, shutdown.DefaultTerminationLogPath, cfg.Shutdown.TerminationLogPath)
+}
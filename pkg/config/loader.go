@@ -16,14 +16,24 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/jpasei/zerohalt/pkg/monitor"
 	"github.com/jpasei/zerohalt/pkg/process"
+	"github.com/jpasei/zerohalt/pkg/proxy"
+	"github.com/jpasei/zerohalt/pkg/shutdown"
 )
 
+// activationShell is checked for when socket activation is enabled.
+var activationShell = process.ActivationShell
+
 func LoadFromEnv() (*Config, error) {
 	cfg := DefaultConfig()
 
@@ -35,6 +45,14 @@ func LoadFromEnv() (*Config, error) {
 		cfg.App.Port = uint16(parsed)
 	}
 
+	if ports := os.Getenv("ZEROHALT_APP_ADDITIONAL_PORTS"); ports != "" {
+		parsed, err := parsePorts(ports)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_APP_ADDITIONAL_PORTS: %w", err)
+		}
+		cfg.App.AdditionalPorts = parsed
+	}
+
 	if healthURL := os.Getenv("ZEROHALT_APP_HEALTH_URL"); healthURL != "" {
 		cfg.App.HealthURL = healthURL
 	}
@@ -59,6 +77,10 @@ func LoadFromEnv() (*Config, error) {
 		cfg.Health.Path = path
 	}
 
+	if path := os.Getenv("ZEROHALT_PRESTOP_PATH"); path != "" {
+		cfg.Health.PreStopPath = path
+	}
+
 	if mode := os.Getenv("ZEROHALT_HEALTH_MODE"); mode != "" {
 		cfg.Health.Mode = HealthMode(mode)
 	}
@@ -75,6 +97,18 @@ func LoadFromEnv() (*Config, error) {
 		cfg.Health.Command = strings.Fields(command)
 	}
 
+	if watchdog := os.Getenv("ZEROHALT_NOTIFY_WATCHDOG"); watchdog != "" {
+		parsed, err := time.ParseDuration(watchdog)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_NOTIFY_WATCHDOG: %w", err)
+		}
+		cfg.Health.Watchdog = parsed
+	}
+
+	if action := os.Getenv("ZEROHALT_NOTIFY_WATCHDOG_ACTION"); action != "" {
+		cfg.Health.WatchdogAction = WatchdogAction(action)
+	}
+
 	if timeout := os.Getenv("ZEROHALT_DRAIN_TIMEOUT"); timeout != "" {
 		parsed, err := time.ParseDuration(timeout)
 		if err != nil {
@@ -83,6 +117,14 @@ func LoadFromEnv() (*Config, error) {
 		cfg.Shutdown.DrainTimeout = parsed
 	}
 
+	if delay := os.Getenv("ZEROHALT_DRAIN_DELAY"); delay != "" {
+		parsed, err := time.ParseDuration(delay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_DRAIN_DELAY: %w", err)
+		}
+		cfg.Shutdown.DrainDelay = parsed
+	}
+
 	if wait := os.Getenv("ZEROHALT_DRAIN_STEADY_STATE_WAIT"); wait != "" {
 		parsed, err := time.ParseDuration(wait)
 		if err != nil {
@@ -99,10 +141,94 @@ func LoadFromEnv() (*Config, error) {
 		cfg.Shutdown.ShutdownTimeout = parsed
 	}
 
+	if budget := os.Getenv("ZEROHALT_SHUTDOWN_BUDGET"); budget != "" {
+		parsed, err := time.ParseDuration(budget)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_SHUTDOWN_BUDGET: %w", err)
+		}
+		cfg.Shutdown.Budget = parsed
+	}
+
+	if percent := os.Getenv("ZEROHALT_DRAIN_BUDGET_PERCENT"); percent != "" {
+		parsed, err := strconv.Atoi(percent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_DRAIN_BUDGET_PERCENT: %w", err)
+		}
+		cfg.Shutdown.DrainBudgetPercent = parsed
+	}
+
+	if minimum := os.Getenv("ZEROHALT_DRAIN_BUDGET_MIN"); minimum != "" {
+		parsed, err := time.ParseDuration(minimum)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_DRAIN_BUDGET_MIN: %w", err)
+		}
+		cfg.Shutdown.DrainBudgetMin = parsed
+	}
+
+	if minimum := os.Getenv("ZEROHALT_STOP_BUDGET_MIN"); minimum != "" {
+		parsed, err := time.ParseDuration(minimum)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_STOP_BUDGET_MIN: %w", err)
+		}
+		cfg.Shutdown.StopBudgetMin = parsed
+	}
+
+	if linger := os.Getenv("ZEROHALT_SHUTDOWN_LINGER"); linger != "" {
+		parsed, err := time.ParseDuration(linger)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_SHUTDOWN_LINGER: %w", err)
+		}
+		cfg.Shutdown.Linger = parsed
+	}
+
+	if enabled := os.Getenv("ZEROHALT_SHUTDOWN_TIMELINE"); enabled != "" {
+		cfg.Shutdown.Timeline = enabled == "true" || enabled == "1"
+	}
+
+	if path := os.Getenv("ZEROHALT_TERMINATION_LOG_PATH"); path != "" {
+		cfg.Shutdown.TerminationLogPath = path
+	}
+
+	if enabled := os.Getenv("ZEROHALT_FORCE_CLOSE_CONNECTIONS"); enabled != "" {
+		cfg.Shutdown.ForceCloseConnections = enabled == "true" || enabled == "1"
+	}
+
+	if after := os.Getenv("ZEROHALT_FORCE_CLOSE_AFTER"); after != "" {
+		parsed, err := time.ParseDuration(after)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_FORCE_CLOSE_AFTER: %w", err)
+		}
+		cfg.Shutdown.ForceCloseAfter = parsed
+	}
+
+	if age := os.Getenv("ZEROHALT_MAX_CONNECTION_AGE"); age != "" {
+		parsed, err := time.ParseDuration(age)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_MAX_CONNECTION_AGE: %w", err)
+		}
+		cfg.Shutdown.MaxConnectionAge = parsed
+	}
+
 	if signal := os.Getenv("ZEROHALT_SIGNAL_TO_APP"); signal != "" {
 		cfg.Shutdown.SignalToApp = signal
 	}
 
+	if action := os.Getenv("ZEROHALT_REPEAT_SIGNAL_ACTION"); action != "" {
+		cfg.Shutdown.RepeatSignalAction = shutdown.RepeatSignalAction(action)
+	}
+
+	if signal := os.Getenv("ZEROHALT_DRAIN_NOTIFY_SIGNAL"); signal != "" {
+		cfg.Shutdown.DrainNotifySignal = signal
+	}
+
+	if url := os.Getenv("ZEROHALT_DRAIN_NOTIFY_URL"); url != "" {
+		cfg.Shutdown.DrainNotifyURL = url
+	}
+
+	if path := os.Getenv("ZEROHALT_DRAIN_NOTIFY_FILE"); path != "" {
+		cfg.Shutdown.DrainNotifyFile = path
+	}
+
 	if level := os.Getenv("ZEROHALT_LOG_LEVEL"); level != "" {
 		cfg.Logging.Level = level
 	}
@@ -115,6 +241,14 @@ func LoadFromEnv() (*Config, error) {
 		cfg.Signal.ShutdownSignals = strings.Split(shutdown, ",")
 	}
 
+	if restart := os.Getenv("ZEROHALT_RESTART_SIGNAL"); restart != "" {
+		cfg.Signal.RestartSignal = restart
+	}
+
+	if cancel := os.Getenv("ZEROHALT_CANCEL_DRAIN_SIGNAL"); cancel != "" {
+		cfg.Signal.CancelDrainSignal = cancel
+	}
+
 	if enabled := os.Getenv("ZEROHALT_METRICS_ENABLED"); enabled != "" {
 		cfg.Metrics.Enabled = enabled == "true" || enabled == "1"
 	}
@@ -131,9 +265,247 @@ func LoadFromEnv() (*Config, error) {
 		cfg.Metrics.Path = path
 	}
 
+	if cidrs := os.Getenv("ZEROHALT_MONITOR_INCLUDE_CIDRS"); cidrs != "" {
+		cfg.Monitor.IncludeCIDRs = strings.Split(cidrs, ",")
+	}
+
+	if cidrs := os.Getenv("ZEROHALT_MONITOR_EXCLUDE_CIDRS"); cidrs != "" {
+		cfg.Monitor.ExcludeCIDRs = strings.Split(cidrs, ",")
+	}
+
+	if exclude := os.Getenv("ZEROHALT_MONITOR_EXCLUDE_LOOPBACK"); exclude != "" {
+		cfg.Monitor.ExcludeLoopback = exclude == "true" || exclude == "1"
+	}
+
+	if treeOnly := os.Getenv("ZEROHALT_MONITOR_PROCESS_TREE_ONLY"); treeOnly != "" {
+		cfg.Monitor.ProcessTreeOnly = treeOnly == "true" || treeOnly == "1"
+	}
+
+	if discover := os.Getenv("ZEROHALT_MONITOR_DISCOVER_PORTS"); discover != "" {
+		cfg.Monitor.DiscoverPorts = discover == "true" || discover == "1"
+	}
+
+	if interval := os.Getenv("ZEROHALT_MONITOR_DISCOVERY_INTERVAL"); interval != "" {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_MONITOR_DISCOVERY_INTERVAL: %w", err)
+		}
+		cfg.Monitor.DiscoveryInterval = parsed
+	}
+
+	if paths := os.Getenv("ZEROHALT_MONITOR_UNIX_SOCKETS"); paths != "" {
+		cfg.Monitor.UnixSocketPaths = strings.Split(paths, ",")
+	}
+
+	if ports := os.Getenv("ZEROHALT_MONITOR_UDP_PORTS"); ports != "" {
+		parsed, err := parsePorts(ports)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_MONITOR_UDP_PORTS: %w", err)
+		}
+		cfg.Monitor.UDPPorts = parsed
+	}
+
+	if signal := os.Getenv("ZEROHALT_MONITOR_UDP_SIGNAL"); signal != "" {
+		cfg.Monitor.UDPSignal = monitor.UDPSignal(signal)
+	}
+
+	if timeout := os.Getenv("ZEROHALT_MONITOR_UDP_IDLE_TIMEOUT"); timeout != "" {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_MONITOR_UDP_IDLE_TIMEOUT: %w", err)
+		}
+		cfg.Monitor.UDPIdleTimeout = parsed
+	}
+
+	if enabled := os.Getenv("ZEROHALT_PROXY_ENABLED"); enabled != "" {
+		cfg.Proxy.Enabled = enabled == "true" || enabled == "1"
+	}
+
+	if port := os.Getenv("ZEROHALT_PROXY_PORT"); port != "" {
+		parsed, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_PROXY_PORT: %w", err)
+		}
+		cfg.Proxy.Port = uint16(parsed)
+	}
+
+	if mode := os.Getenv("ZEROHALT_PROXY_MODE"); mode != "" {
+		cfg.Proxy.Mode = ProxyMode(mode)
+	}
+
+	if grace := os.Getenv("ZEROHALT_PROXY_DRAIN_GRACE"); grace != "" {
+		parsed, err := time.ParseDuration(grace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_PROXY_DRAIN_GRACE: %w", err)
+		}
+		cfg.Proxy.DrainGrace = parsed
+	}
+
+	if closeMode := os.Getenv("ZEROHALT_PROXY_DRAIN_CLOSE_MODE"); closeMode != "" {
+		cfg.Proxy.DrainCloseMode = proxy.CloseMode(closeMode)
+	}
+
+	if size := os.Getenv("ZEROHALT_PROXY_HOLD_QUEUE_SIZE"); size != "" {
+		parsed, err := strconv.Atoi(size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_PROXY_HOLD_QUEUE_SIZE: %w", err)
+		}
+		cfg.Proxy.HoldQueueSize = parsed
+	}
+
+	if timeout := os.Getenv("ZEROHALT_PROXY_HOLD_TIMEOUT"); timeout != "" {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_PROXY_HOLD_TIMEOUT: %w", err)
+		}
+		cfg.Proxy.HoldTimeout = parsed
+	}
+
+	if concurrency := os.Getenv("ZEROHALT_PROXY_MAX_CONCURRENCY"); concurrency != "" {
+		parsed, err := strconv.Atoi(concurrency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_PROXY_MAX_CONCURRENCY: %w", err)
+		}
+		cfg.Proxy.MaxConcurrency = parsed
+	}
+
+	if size := os.Getenv("ZEROHALT_PROXY_QUEUE_SIZE"); size != "" {
+		parsed, err := strconv.Atoi(size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_PROXY_QUEUE_SIZE: %w", err)
+		}
+		cfg.Proxy.QueueSize = parsed
+	}
+
+	if timeout := os.Getenv("ZEROHALT_PROXY_QUEUE_TIMEOUT"); timeout != "" {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_PROXY_QUEUE_TIMEOUT: %w", err)
+		}
+		cfg.Proxy.QueueTimeout = parsed
+	}
+
+	if adaptive := os.Getenv("ZEROHALT_PROXY_ADAPTIVE_CONCURRENCY"); adaptive != "" {
+		cfg.Proxy.AdaptiveConcurrency = adaptive == "true" || adaptive == "1"
+	}
+
+	if concurrency := os.Getenv("ZEROHALT_PROXY_MIN_CONCURRENCY"); concurrency != "" {
+		parsed, err := strconv.Atoi(concurrency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_PROXY_MIN_CONCURRENCY: %w", err)
+		}
+		cfg.Proxy.MinConcurrency = parsed
+	}
+
+	if target := os.Getenv("ZEROHALT_PROXY_LATENCY_TARGET"); target != "" {
+		parsed, err := time.ParseDuration(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_PROXY_LATENCY_TARGET: %w", err)
+		}
+		cfg.Proxy.LatencyTarget = parsed
+	}
+
+	if enabled := os.Getenv("ZEROHALT_SOCKET_ACTIVATION"); enabled != "" {
+		cfg.Activation.Enabled = enabled == "true" || enabled == "1"
+	}
+
+	if names := os.Getenv("ZEROHALT_SOCKET_ACTIVATION_NAMES"); names != "" {
+		cfg.Activation.Names = strings.Split(names, ",")
+	}
+
+	if token := os.Getenv("ZEROHALT_ADMIN_TOKEN"); token != "" {
+		cfg.Admin.Token = token
+	}
+
+	if timeout := os.Getenv("ZEROHALT_HOOK_TIMEOUT"); timeout != "" {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_HOOK_TIMEOUT: %w", err)
+		}
+		cfg.Hooks.Timeout = parsed
+	}
+
+	if retries := os.Getenv("ZEROHALT_HOOK_RETRIES"); retries != "" {
+		parsed, err := strconv.Atoi(retries)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZEROHALT_HOOK_RETRIES: %w", err)
+		}
+		cfg.Hooks.Retries = parsed
+	}
+
+	phases := []struct {
+		prefix string
+		hooks  *[]HookConfig
+	}{
+		{"ZEROHALT_PRE_START_HOOK", &cfg.Hooks.PreStart},
+		{"ZEROHALT_PRE_DRAIN_HOOK", &cfg.Hooks.PreDrain},
+		{"ZEROHALT_PRE_STOP_HOOK", &cfg.Hooks.PreStop},
+		{"ZEROHALT_POST_STOP_HOOK", &cfg.Hooks.PostStop},
+	}
+	for _, phase := range phases {
+		phaseHooks, err := loadHooks(phase.prefix, cfg.Hooks)
+		if err != nil {
+			return nil, err
+		}
+		*phase.hooks = phaseHooks
+	}
+
 	return cfg, cfg.Validate()
 }
 
+// loadHooks reads numbered hooks (PREFIX_1, PREFIX_2, ...) up to the first
+// gap. PREFIX_N_TIMEOUT and PREFIX_N_RETRIES override the defaults.
+// A value that is a URL, optionally preceded by an HTTP method, is an HTTP
+// hook; anything else is a command.
+func loadHooks(prefix string, defaults HooksConfig) ([]HookConfig, error) {
+	hooks := []HookConfig{}
+
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("%s_%d", prefix, n)
+
+		command := os.Getenv(name)
+		if command == "" {
+			return hooks, nil
+		}
+
+		hook := parseHook(command)
+		hook.Timeout = defaults.Timeout
+		hook.Retries = defaults.Retries
+
+		if timeout := os.Getenv(name + "_TIMEOUT"); timeout != "" {
+			parsed, err := time.ParseDuration(timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s_TIMEOUT: %w", name, err)
+			}
+			hook.Timeout = parsed
+		}
+
+		if retries := os.Getenv(name + "_RETRIES"); retries != "" {
+			parsed, err := strconv.Atoi(retries)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s_RETRIES: %w", name, err)
+			}
+			hook.Retries = parsed
+		}
+
+		hooks = append(hooks, hook)
+	}
+}
+
+func parsePorts(value string) ([]uint16, error) {
+	var ports []uint16
+
+	for _, field := range strings.Split(value, ",") {
+		parsed, err := strconv.ParseUint(strings.TrimSpace(field), 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, uint16(parsed))
+	}
+
+	return ports, nil
+}
+
 func (c *Config) Validate() error {
 	if c.Health.Port == 0 {
 		return fmt.Errorf("health check port must be specified")
@@ -148,6 +520,7 @@ func (c *Config) Validate() error {
 		HealthModeAppDependent: true,
 		HealthModeHybrid:       true,
 		HealthModeCommand:      true,
+		HealthModeNotify:       true,
 	}
 	if !validModes[c.Health.Mode] {
 		return fmt.Errorf("invalid health mode: %s", c.Health.Mode)
@@ -157,10 +530,40 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("drain timeout must be positive")
 	}
 
+	if c.Shutdown.DrainDelay < 0 {
+		return fmt.Errorf("drain delay must not be negative")
+	}
+
+	if err := c.validateBudget(); err != nil {
+		return err
+	}
+
+	drainLimit, drainLimitName := c.drainLimit()
+
+	if c.Shutdown.DrainDelay >= drainLimit {
+		return fmt.Errorf("drain delay must be less than %s", drainLimitName)
+	}
+
 	if c.Shutdown.ShutdownTimeout <= 0 {
 		return fmt.Errorf("shutdown timeout must be positive")
 	}
 
+	if c.Shutdown.Linger < 0 {
+		return fmt.Errorf("shutdown linger must not be negative")
+	}
+
+	if c.Shutdown.MaxConnectionAge < 0 {
+		return fmt.Errorf("max connection age must not be negative")
+	}
+
+	if c.Shutdown.ForceCloseConnections && c.Shutdown.ForceCloseAfter < 0 {
+		return fmt.Errorf("force close delay must not be negative")
+	}
+
+	if c.Shutdown.ForceCloseConnections && c.Shutdown.ForceCloseAfter >= drainLimit {
+		return fmt.Errorf("force close delay must be less than %s", drainLimitName)
+	}
+
 	for _, sig := range c.Signal.PassThroughSignals {
 		if process.ParseSignal(sig) == nil {
 			return fmt.Errorf("invalid pass-through signal: %s", sig)
@@ -177,6 +580,415 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if c.Monitor.DiscoverPorts && c.Monitor.DiscoveryInterval <= 0 {
+		return fmt.Errorf("port discovery interval must be positive")
+	}
+
+	for _, path := range c.Monitor.UnixSocketPaths {
+		isAbsolute := strings.HasPrefix(path, "/")
+		isAbstract := strings.HasPrefix(path, "@")
+
+		if !isAbsolute && !isAbstract {
+			return fmt.Errorf("unix socket path must be absolute or abstract: %s", path)
+		}
+	}
+
+	validUDPSignals := map[monitor.UDPSignal]bool{
+		monitor.UDPSignalQueues:    true,
+		monitor.UDPSignalConnected: true,
+	}
+	if !validUDPSignals[c.Monitor.UDPSignal] {
+		return fmt.Errorf("invalid UDP drain signal: %s", c.Monitor.UDPSignal)
+	}
+
+	if c.Monitor.UDPIdleTimeout < 0 {
+		return fmt.Errorf("UDP idle timeout must not be negative")
+	}
+
+	for _, cidr := range c.Monitor.IncludeCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid include CIDR: %s", cidr)
+		}
+	}
+
+	for _, cidr := range c.Monitor.ExcludeCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid exclude CIDR: %s", cidr)
+		}
+	}
+
+	if err := c.validateProxy(); err != nil {
+		return err
+	}
+
+	validRepeatActions := map[shutdown.RepeatSignalAction]bool{
+		shutdown.RepeatSignalIgnore:   true,
+		shutdown.RepeatSignalExpedite: true,
+		shutdown.RepeatSignalKill:     true,
+	}
+	if !validRepeatActions[c.Shutdown.RepeatSignalAction] {
+		return fmt.Errorf("invalid repeat signal action: %s", c.Shutdown.RepeatSignalAction)
+	}
+
+	if err := c.validateDrainNotify(); err != nil {
+		return err
+	}
+
+	if path := c.Shutdown.TerminationLogPath; c.Shutdown.Timeline && !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("termination log path must be absolute: %s", path)
+	}
+
+	if err := c.validateAllHooks(); err != nil {
+		return err
+	}
+
+	if err := c.validateWatchdog(); err != nil {
+		return err
+	}
+
+	if err := c.validatePreStop(); err != nil {
+		return err
+	}
+
+	if err := c.validateActivation(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func parseHook(value string) HookConfig {
+	fields := strings.Fields(value)
+
+	if len(fields) == 1 && isHookURL(fields[0]) {
+		return HookConfig{Method: http.MethodPost, URL: fields[0]}
+	}
+
+	if len(fields) == 2 && isHookURL(fields[1]) {
+		return HookConfig{Method: strings.ToUpper(fields[0]), URL: fields[1]}
+	}
+
+	return HookConfig{Command: fields}
+}
+
+func isHookURL(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
+
+func validateHooks(phase string, hooks []HookConfig) error {
+	validMethods := map[string]bool{
+		http.MethodGet:    true,
+		http.MethodPost:   true,
+		http.MethodPut:    true,
+		http.MethodDelete: true,
+		http.MethodPatch:  true,
+	}
+
+	for i, hook := range hooks {
+		if hook.URL != "" {
+			if _, err := url.ParseRequestURI(hook.URL); err != nil {
+				return fmt.Errorf("invalid %s hook %d URL: %s", phase, i+1, hook.URL)
+			}
+
+			if !validMethods[hook.Method] {
+				return fmt.Errorf("invalid %s hook %d method: %s", phase, i+1, hook.Method)
+			}
+		}
+
+		if hook.Timeout <= 0 {
+			return fmt.Errorf("%s hook %d timeout must be positive", phase, i+1)
+		}
+
+		if hook.Retries < 0 {
+			return fmt.Errorf("%s hook %d retries must not be negative", phase, i+1)
+		}
+	}
+
+	return nil
+}
+
+// ShutdownBudget returns the shutdown budget, which is disabled unless
+// ZEROHALT_SHUTDOWN_BUDGET is set.
+func (c *Config) ShutdownBudget() shutdown.Budget {
+	return shutdown.Budget{
+		Total:        c.Shutdown.Budget,
+		DrainPercent: c.Shutdown.DrainBudgetPercent,
+		DrainMin:     c.Shutdown.DrainBudgetMin,
+		StopMin:      c.Shutdown.StopBudgetMin,
+	}
+}
+
+// drainLimit returns how long the drain phase may run and what sets it.
+func (c *Config) drainLimit() (time.Duration, string) {
+	budget := c.ShutdownBudget()
+	if budget.Enabled() {
+		return budget.DrainLimit(), "drain budget"
+	}
+	return c.Shutdown.DrainTimeout, "drain timeout"
+}
+
+func (c *Config) validateBudget() error {
+	if c.Shutdown.Budget < 0 {
+		return fmt.Errorf("shutdown budget must not be negative")
+	}
+
+	if c.Shutdown.DrainBudgetPercent < 0 || c.Shutdown.DrainBudgetPercent > 100 {
+		return fmt.Errorf("drain budget percent must be between 0 and 100")
+	}
+
+	if c.Shutdown.DrainBudgetMin < 0 || c.Shutdown.StopBudgetMin < 0 {
+		return fmt.Errorf("budget minimums must not be negative")
+	}
+
+	return nil
+}
+
+// Warnings returns configuration problems that do not stop Zerohalt from
+// running, such as shutdown phases that cannot fit in the shutdown budget.
+func (c *Config) Warnings() []string {
+	budget := c.ShutdownBudget()
+	if !budget.Enabled() {
+		return nil
+	}
+
+	var warnings []string
+
+	if minimums := budget.DrainMin + budget.StopMin; minimums > budget.Total {
+		warnings = append(warnings, fmt.Sprintf("drain and stop budget minimums (%s) exceed the shutdown budget (%s); the drain gets %s", minimums, budget.Total, budget.DrainLimit()))
+	}
+
+	drainPhase := c.Shutdown.DrainDelay + hooksDuration(c.Hooks.PreDrain)
+	if drainPhase > budget.DrainLimit() {
+		warnings = append(warnings, fmt.Sprintf("drain delay and pre-drain hook timeouts (%s) exceed the drain budget (%s)", drainPhase, budget.DrainLimit()))
+	}
+
+	if stopHooks := hooksDuration(c.Hooks.PreStop) + hooksDuration(c.Hooks.PostStop); stopHooks > budget.StopLimit() {
+		warnings = append(warnings, fmt.Sprintf("pre-stop and post-stop hook timeouts (%s) exceed the stop budget (%s)", stopHooks, budget.StopLimit()))
+	}
+
+	if c.Shutdown.Linger > 0 {
+		warnings = append(warnings, fmt.Sprintf("shutdown linger (%s) runs after the shutdown budget (%s) is spent", c.Shutdown.Linger, budget.Total))
+	}
+
+	return warnings
+}
+
+// hooksDuration is the longest the hooks can take, counting every retry.
+func hooksDuration(hooks []HookConfig) time.Duration {
+	var total time.Duration
+	for _, hook := range hooks {
+		total += hook.Timeout * time.Duration(hook.Retries+1)
+	}
+	return total
+}
+
+func (c *Config) validatePreStop() error {
+	path := c.Health.PreStopPath
+	if path == "" {
+		return nil
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("preStop path must start with /: %s", path)
+	}
+
+	metricsOnHealthPort := c.Metrics.Enabled && c.Metrics.Port == c.Health.Port
+	conflicts := path == c.Health.Path || (metricsOnHealthPort && path == c.Metrics.Path)
+	if conflicts {
+		return fmt.Errorf("preStop path %s conflicts with another endpoint on the health port", path)
+	}
+
+	return nil
+}
+
+func (c *Config) validateDrainNotify() error {
+	if signal := c.Shutdown.DrainNotifySignal; signal != "" {
+		parsed := process.ParseSignal(signal)
+		if parsed == nil {
+			return fmt.Errorf("invalid drain notify signal: %s", signal)
+		}
+
+		stopSignals := append([]string{c.Shutdown.SignalToApp}, c.Signal.ShutdownSignals...)
+		for _, stop := range stopSignals {
+			if process.ParseSignal(stop) == parsed {
+				return fmt.Errorf("drain notify signal %s must differ from the signal that stops the app", signal)
+			}
+		}
+	}
+
+	if notifyURL := c.Shutdown.DrainNotifyURL; notifyURL != "" && !isHookURL(notifyURL) {
+		return fmt.Errorf("drain notify URL must be http or https: %s", notifyURL)
+	}
+
+	if path := c.Shutdown.DrainNotifyFile; path != "" && !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("drain notify file must be an absolute path: %s", path)
+	}
+
+	return nil
+}
+
+func (c *Config) validateAllHooks() error {
+	phases := []struct {
+		name  string
+		hooks []HookConfig
+	}{
+		{"pre-start", c.Hooks.PreStart},
+		{"pre-drain", c.Hooks.PreDrain},
+		{"pre-stop", c.Hooks.PreStop},
+		{"post-stop", c.Hooks.PostStop},
+	}
+
+	for _, phase := range phases {
+		if err := validateHooks(phase.name, phase.hooks); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateWatchdog() error {
+	if c.Health.Watchdog < 0 {
+		return fmt.Errorf("notify watchdog must not be negative")
+	}
+
+	validActions := map[WatchdogAction]bool{
+		WatchdogActionUnhealthy: true,
+		WatchdogActionRestart:   true,
+	}
+	if !validActions[c.Health.WatchdogAction] {
+		return fmt.Errorf("invalid notify watchdog action: %s", c.Health.WatchdogAction)
+	}
+
+	if c.Health.Watchdog > 0 && c.Health.Mode != HealthModeNotify {
+		return fmt.Errorf("notify watchdog requires health mode %s", HealthModeNotify)
+	}
+
+	return nil
+}
+
+func (c *Config) validateActivation() error {
+	if !c.Activation.Enabled {
+		return nil
+	}
+
+	if _, err := exec.LookPath(activationShell); err != nil {
+		return fmt.Errorf("socket activation runs the application through %s, which is not available: %w", activationShell, err)
+	}
+
+	listenerCount := 1 + len(c.App.AdditionalPorts)
+	hasNames := len(c.Activation.Names) > 0
+
+	if hasNames && len(c.Activation.Names) != listenerCount {
+		return fmt.Errorf("socket activation needs one name per app port, got %d names for %d ports", len(c.Activation.Names), listenerCount)
+	}
+
+	for _, name := range c.Activation.Names {
+		if name == "" || strings.Contains(name, ":") {
+			return fmt.Errorf("invalid socket activation name: %q", name)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateProxy() error {
+	if !c.Proxy.Enabled {
+		return nil
+	}
+
+	if c.Proxy.Port == 0 {
+		return fmt.Errorf("proxy port must be specified when proxy is enabled")
+	}
+
+	validModes := map[ProxyMode]bool{
+		ProxyModeHTTP: true,
+		ProxyModeTCP:  true,
+	}
+	if !validModes[c.Proxy.Mode] {
+		return fmt.Errorf("invalid proxy mode: %s", c.Proxy.Mode)
+	}
+
+	if c.Proxy.Port == c.App.Port {
+		return fmt.Errorf("proxy port must differ from app port")
+	}
+
+	if c.Proxy.Port == c.Health.Port {
+		return fmt.Errorf("proxy port must differ from health check port")
+	}
+
+	if c.Metrics.Enabled && c.Proxy.Port == c.Metrics.Port {
+		return fmt.Errorf("proxy port must differ from metrics port")
+	}
+
+	if c.Proxy.HoldQueueSize <= 0 {
+		return fmt.Errorf("proxy hold queue size must be positive")
+	}
+
+	if c.Proxy.HoldTimeout <= 0 {
+		return fmt.Errorf("proxy hold timeout must be positive")
+	}
+
+	if c.Proxy.Mode == ProxyModeTCP {
+		return c.validateTCPProxy()
+	}
+
+	return c.validateConcurrencyLimit()
+}
+
+func (c *Config) validateTCPProxy() error {
+	if c.Proxy.DrainGrace < 0 {
+		return fmt.Errorf("proxy drain grace period must not be negative")
+	}
+
+	validCloseModes := map[proxy.CloseMode]bool{
+		proxy.CloseModeHalfClose: true,
+		proxy.CloseModeReset:     true,
+	}
+	if !validCloseModes[c.Proxy.DrainCloseMode] {
+		return fmt.Errorf("invalid proxy drain close mode: %s", c.Proxy.DrainCloseMode)
+	}
+
+	if c.Proxy.MaxConcurrency > 0 {
+		return fmt.Errorf("proxy max concurrency is only supported in http proxy mode")
+	}
+
+	return nil
+}
+
+func (c *Config) validateConcurrencyLimit() error {
+	if c.Proxy.MaxConcurrency < 0 {
+		return fmt.Errorf("proxy max concurrency must not be negative")
+	}
+
+	limitEnabled := c.Proxy.MaxConcurrency > 0
+	if !limitEnabled {
+		if c.Proxy.AdaptiveConcurrency {
+			return fmt.Errorf("adaptive concurrency requires a proxy max concurrency")
+		}
+		return nil
+	}
+
+	if c.Proxy.QueueSize < 0 {
+		return fmt.Errorf("proxy queue size must not be negative")
+	}
+
+	if c.Proxy.QueueTimeout <= 0 {
+		return fmt.Errorf("proxy queue timeout must be positive")
+	}
+
+	if !c.Proxy.AdaptiveConcurrency {
+		return nil
+	}
+
+	if c.Proxy.MinConcurrency < 1 || c.Proxy.MinConcurrency > c.Proxy.MaxConcurrency {
+		return fmt.Errorf("proxy min concurrency must be between 1 and max concurrency")
+	}
+
+	if c.Proxy.LatencyTarget <= 0 {
+		return fmt.Errorf("proxy latency target must be positive")
+	}
+
 	return nil
 }
 
@@ -193,5 +1005,71 @@ func (c *Config) validateSignalConflicts() error {
 		}
 	}
 
+	if err := c.validateRestartSignal(shutdownMap); err != nil {
+		return err
+	}
+
+	return c.validateCancelDrainSignal(shutdownMap)
+}
+
+func (c *Config) validateRestartSignal(shutdownMap map[string]bool) error {
+	restart := c.Signal.RestartSignal
+	if restart == "" {
+		return nil
+	}
+
+	if process.ParseSignal(restart) == nil {
+		return fmt.Errorf("invalid restart signal: %s", restart)
+	}
+
+	if shutdownMap[restart] {
+		return fmt.Errorf("signal %s cannot be both restart and shutdown signal", restart)
+	}
+
+	for _, pt := range c.Signal.PassThroughSignals {
+		if pt == restart {
+			return fmt.Errorf("signal %s cannot be both restart and pass-through signal", restart)
+		}
+	}
+
+	if !c.Proxy.Enabled || c.Proxy.Mode != ProxyModeHTTP {
+		return fmt.Errorf("restart signal requires http proxy mode")
+	}
+
+	return nil
+}
+
+func (c *Config) validateCancelDrainSignal(shutdownMap map[string]bool) error {
+	cancel := c.Signal.CancelDrainSignal
+	if cancel == "" {
+		return nil
+	}
+
+	if process.ParseSignal(cancel) == nil {
+		return fmt.Errorf("invalid cancel drain signal: %s", cancel)
+	}
+
+	if shutdownMap[cancel] {
+		return fmt.Errorf("signal %s cannot be both cancel drain and shutdown signal", cancel)
+	}
+
+	for _, pt := range c.Signal.PassThroughSignals {
+		if pt == cancel {
+			return fmt.Errorf("signal %s cannot be both cancel drain and pass-through signal", cancel)
+		}
+	}
+
+	if cancel == c.Signal.RestartSignal {
+		return fmt.Errorf("signal %s cannot be both cancel drain and restart signal", cancel)
+	}
+
+	if cancel == c.Shutdown.DrainNotifySignal {
+		return fmt.Errorf("signal %s cannot be both cancel drain and drain notify signal", cancel)
+	}
+
+	if c.Proxy.Enabled && c.Proxy.Mode == ProxyModeTCP {
+		return fmt.Errorf("cancel drain signal is not supported in tcp proxy mode")
+	}
+
 	return nil
 }
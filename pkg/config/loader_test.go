@@ -19,6 +19,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jpasei/zerohalt/pkg/monitor"
+	"github.com/jpasei/zerohalt/pkg/proxy"
+	"github.com/jpasei/zerohalt/pkg/shutdown"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -356,3 +359,1085 @@ func TestLoadFromEnv_InvalidDrainSteadyStateWait(t *testing.T) {
 	_, err := LoadFromEnv()
 	assert.Error(t, err)
 }
+
+func TestLoadFromEnv_ForceClose(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_FORCE_CLOSE_CONNECTIONS", "true")
+	os.Setenv("ZEROHALT_FORCE_CLOSE_AFTER", "20s")
+	os.Setenv("ZEROHALT_MAX_CONNECTION_AGE", "5m")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.True(t, cfg.Shutdown.ForceCloseConnections)
+	assert.Equal(t, 20*time.Second, cfg.Shutdown.ForceCloseAfter)
+	assert.Equal(t, 5*time.Minute, cfg.Shutdown.MaxConnectionAge)
+}
+
+func TestLoadFromEnv_InvalidForceCloseAfter(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_FORCE_CLOSE_AFTER", "invalid")
+	defer os.Clearenv()
+
+	_, err := LoadFromEnv()
+	assert.Error(t, err)
+}
+
+func TestLoadFromEnv_InvalidMaxConnectionAge(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_MAX_CONNECTION_AGE", "invalid")
+	defer os.Clearenv()
+
+	_, err := LoadFromEnv()
+	assert.Error(t, err)
+}
+
+func TestValidate_NegativeMaxConnectionAge(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Shutdown.MaxConnectionAge = -1 * time.Second
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+}
+
+func TestValidate_ForceCloseAfterExceedsDrainTimeout(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Shutdown.ForceCloseConnections = true
+	cfg.Shutdown.ForceCloseAfter = cfg.Shutdown.DrainTimeout
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+}
+
+func TestValidate_NegativeForceCloseAfter(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Shutdown.ForceCloseConnections = true
+	cfg.Shutdown.ForceCloseAfter = -1 * time.Second
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+}
+
+func TestLoadFromEnv_MonitorFilters(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_MONITOR_INCLUDE_CIDRS", "10.0.0.0/8,fd00::/8")
+	os.Setenv("ZEROHALT_MONITOR_EXCLUDE_CIDRS", "10.0.0.0/24")
+	os.Setenv("ZEROHALT_MONITOR_EXCLUDE_LOOPBACK", "true")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "fd00::/8"}, cfg.Monitor.IncludeCIDRs)
+	assert.Equal(t, []string{"10.0.0.0/24"}, cfg.Monitor.ExcludeCIDRs)
+	assert.True(t, cfg.Monitor.ExcludeLoopback)
+}
+
+func TestValidate_InvalidIncludeCIDR(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Monitor.IncludeCIDRs = []string{"10.0.0.1"}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+}
+
+func TestValidate_InvalidExcludeCIDR(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Monitor.ExcludeCIDRs = []string{"invalid"}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+}
+
+func TestLoadFromEnv_MonitorProcessTreeOnly(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_MONITOR_PROCESS_TREE_ONLY", "true")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.True(t, cfg.Monitor.ProcessTreeOnly)
+}
+
+func TestLoadFromEnv_AdditionalPorts(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_APP_ADDITIONAL_PORTS", "9090, 9091")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{9090, 9091}, cfg.App.AdditionalPorts)
+}
+
+func TestLoadFromEnv_InvalidAdditionalPorts(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_APP_ADDITIONAL_PORTS", "9090,70000")
+	defer os.Clearenv()
+
+	_, err := LoadFromEnv()
+	assert.Error(t, err)
+}
+
+func TestLoadFromEnv_PortDiscovery(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_MONITOR_DISCOVER_PORTS", "true")
+	os.Setenv("ZEROHALT_MONITOR_DISCOVERY_INTERVAL", "15s")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.True(t, cfg.Monitor.DiscoverPorts)
+	assert.Equal(t, 15*time.Second, cfg.Monitor.DiscoveryInterval)
+}
+
+func TestLoadFromEnv_InvalidDiscoveryInterval(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_MONITOR_DISCOVERY_INTERVAL", "invalid")
+	defer os.Clearenv()
+
+	_, err := LoadFromEnv()
+	assert.Error(t, err)
+}
+
+func TestValidate_InvalidDiscoveryInterval(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Monitor.DiscoverPorts = true
+	cfg.Monitor.DiscoveryInterval = 0
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+}
+
+func TestLoadFromEnv_UnixSockets(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_MONITOR_UNIX_SOCKETS", "/run/app.sock,@abstract")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/run/app.sock", "@abstract"}, cfg.Monitor.UnixSocketPaths)
+}
+
+func TestValidate_RelativeUnixSocketPath(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Monitor.UnixSocketPaths = []string{"run/app.sock"}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+}
+
+func TestLoadFromEnv_UDPMonitoring(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_MONITOR_UDP_PORTS", "443,8443")
+	os.Setenv("ZEROHALT_MONITOR_UDP_SIGNAL", "connected")
+	os.Setenv("ZEROHALT_MONITOR_UDP_IDLE_TIMEOUT", "1m")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{443, 8443}, cfg.Monitor.UDPPorts)
+	assert.Equal(t, monitor.UDPSignalConnected, cfg.Monitor.UDPSignal)
+	assert.Equal(t, 1*time.Minute, cfg.Monitor.UDPIdleTimeout)
+}
+
+func TestLoadFromEnv_InvalidUDPPorts(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_MONITOR_UDP_PORTS", "quic")
+	defer os.Clearenv()
+
+	_, err := LoadFromEnv()
+	assert.Error(t, err)
+}
+
+func TestLoadFromEnv_InvalidUDPIdleTimeout(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_MONITOR_UDP_IDLE_TIMEOUT", "invalid")
+	defer os.Clearenv()
+
+	_, err := LoadFromEnv()
+	assert.Error(t, err)
+}
+
+func TestValidate_InvalidUDPSignal(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Monitor.UDPSignal = "packets"
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+}
+
+func TestValidate_NegativeUDPIdleTimeout(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Monitor.UDPIdleTimeout = -1 * time.Second
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+}
+
+func TestLoadFromEnv_Proxy(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_PROXY_ENABLED", "true")
+	os.Setenv("ZEROHALT_PROXY_PORT", "80")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.True(t, cfg.Proxy.Enabled)
+	assert.Equal(t, uint16(80), cfg.Proxy.Port)
+}
+
+func TestLoadFromEnv_InvalidProxyPort(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_PROXY_PORT", "http")
+	defer os.Clearenv()
+
+	_, err := LoadFromEnv()
+	assert.Error(t, err)
+}
+
+func TestValidate_Proxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		port    uint16
+		wantErr string
+	}{
+		{"missing port", 0, "proxy port must be specified"},
+		{"same as app port", 8080, "proxy port must differ from app port"},
+		{"same as health port", 8888, "proxy port must differ from health check port"},
+		{"valid", 80, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Proxy.Enabled = true
+			cfg.Proxy.Port = tt.port
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_ProxyMetricsPortConflict(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Proxy.Enabled = true
+	cfg.Proxy.Port = 9100
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.Port = 9100
+
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "proxy port must differ from metrics port")
+}
+
+func TestLoadFromEnv_Restart(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_PROXY_ENABLED", "true")
+	os.Setenv("ZEROHALT_PROXY_PORT", "80")
+	os.Setenv("ZEROHALT_PROXY_HOLD_QUEUE_SIZE", "50")
+	os.Setenv("ZEROHALT_PROXY_HOLD_TIMEOUT", "10s")
+	os.Setenv("ZEROHALT_PASSTHROUGH_SIGNALS", "SIGHUP")
+	os.Setenv("ZEROHALT_RESTART_SIGNAL", "SIGUSR2")
+	os.Setenv("ZEROHALT_ADMIN_TOKEN", "secret")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, 50, cfg.Proxy.HoldQueueSize)
+	assert.Equal(t, 10*time.Second, cfg.Proxy.HoldTimeout)
+	assert.Equal(t, "SIGUSR2", cfg.Signal.RestartSignal)
+	assert.Equal(t, "secret", cfg.Admin.Token)
+}
+
+func TestLoadFromEnv_InvalidHoldSettings(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"queue size", "ZEROHALT_PROXY_HOLD_QUEUE_SIZE", "many"},
+		{"timeout", "ZEROHALT_PROXY_HOLD_TIMEOUT", "forever"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(tt.key, tt.value)
+			defer os.Clearenv()
+
+			_, err := LoadFromEnv()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidate_ProxyHoldLimits(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Proxy.Enabled = true
+	cfg.Proxy.Port = 80
+	cfg.Proxy.HoldQueueSize = 0
+	assert.ErrorContains(t, cfg.Validate(), "hold queue size")
+
+	cfg.Proxy.HoldQueueSize = 10
+	cfg.Proxy.HoldTimeout = 0
+	assert.ErrorContains(t, cfg.Validate(), "hold timeout")
+}
+
+func TestValidate_RestartSignal(t *testing.T) {
+	tests := []struct {
+		name    string
+		signal  string
+		proxy   bool
+		wantErr string
+	}{
+		{"invalid", "SIGFOO", true, "invalid restart signal"},
+		{"conflicts with shutdown", "SIGTERM", true, "both restart and shutdown"},
+		{"conflicts with pass-through", "SIGUSR1", true, "both restart and pass-through"},
+		{"requires proxy", "SIGUSR2", false, "requires http proxy mode"},
+		{"valid", "SIGUSR2", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Signal.PassThroughSignals = []string{"SIGHUP", "SIGUSR1"}
+			cfg.Signal.RestartSignal = tt.signal
+			cfg.Proxy.Enabled = tt.proxy
+			cfg.Proxy.Port = 80
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_CancelDrainSignal(t *testing.T) {
+	tests := []struct {
+		name    string
+		signal  string
+		mode    ProxyMode
+		wantErr string
+	}{
+		{"invalid", "SIGFOO", ProxyModeHTTP, "invalid cancel drain signal"},
+		{"conflicts with shutdown", "SIGTERM", ProxyModeHTTP, "both cancel drain and shutdown"},
+		{"conflicts with pass-through", "SIGHUP", ProxyModeHTTP, "both cancel drain and pass-through"},
+		{"conflicts with restart", "SIGUSR2", ProxyModeHTTP, "both cancel drain and restart"},
+		{"conflicts with drain notify", "SIGWINCH", ProxyModeHTTP, "both cancel drain and drain notify"},
+		{"tcp proxy", "SIGUSR1", ProxyModeTCP, "not supported in tcp proxy mode"},
+		{"valid", "SIGUSR1", ProxyModeHTTP, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Signal.PassThroughSignals = []string{"SIGHUP"}
+			if tt.mode == ProxyModeHTTP {
+				cfg.Signal.RestartSignal = "SIGUSR2"
+			}
+			cfg.Signal.CancelDrainSignal = tt.signal
+			cfg.Shutdown.DrainNotifySignal = "SIGWINCH"
+			cfg.Proxy.Enabled = true
+			cfg.Proxy.Mode = tt.mode
+			cfg.Proxy.Port = 80
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromEnv_CancelDrainSignal(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_CANCEL_DRAIN_SIGNAL", "SIGUSR1")
+	os.Setenv("ZEROHALT_PASSTHROUGH_SIGNALS", "SIGHUP")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "SIGUSR1", cfg.Signal.CancelDrainSignal)
+}
+
+func TestLoadFromEnv_ConcurrencyLimit(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_PROXY_ENABLED", "true")
+	os.Setenv("ZEROHALT_PROXY_PORT", "80")
+	os.Setenv("ZEROHALT_PROXY_MAX_CONCURRENCY", "64")
+	os.Setenv("ZEROHALT_PROXY_QUEUE_SIZE", "32")
+	os.Setenv("ZEROHALT_PROXY_QUEUE_TIMEOUT", "2s")
+	os.Setenv("ZEROHALT_PROXY_ADAPTIVE_CONCURRENCY", "true")
+	os.Setenv("ZEROHALT_PROXY_MIN_CONCURRENCY", "4")
+	os.Setenv("ZEROHALT_PROXY_LATENCY_TARGET", "250ms")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, 64, cfg.Proxy.MaxConcurrency)
+	assert.Equal(t, 32, cfg.Proxy.QueueSize)
+	assert.Equal(t, 2*time.Second, cfg.Proxy.QueueTimeout)
+	assert.True(t, cfg.Proxy.AdaptiveConcurrency)
+	assert.Equal(t, 4, cfg.Proxy.MinConcurrency)
+	assert.Equal(t, 250*time.Millisecond, cfg.Proxy.LatencyTarget)
+}
+
+func TestLoadFromEnv_InvalidConcurrencySettings(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"max concurrency", "ZEROHALT_PROXY_MAX_CONCURRENCY", "lots"},
+		{"queue size", "ZEROHALT_PROXY_QUEUE_SIZE", "big"},
+		{"queue timeout", "ZEROHALT_PROXY_QUEUE_TIMEOUT", "soon"},
+		{"min concurrency", "ZEROHALT_PROXY_MIN_CONCURRENCY", "few"},
+		{"latency target", "ZEROHALT_PROXY_LATENCY_TARGET", "fast"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(tt.key, tt.value)
+			defer os.Clearenv()
+
+			_, err := LoadFromEnv()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidate_ConcurrencyLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{"negative max", func(cfg *Config) { cfg.Proxy.MaxConcurrency = -1 }, "max concurrency must not be negative"},
+		{"adaptive without max", func(cfg *Config) { cfg.Proxy.AdaptiveConcurrency = true }, "adaptive concurrency requires"},
+		{"negative queue", func(cfg *Config) { cfg.Proxy.MaxConcurrency = 10; cfg.Proxy.QueueSize = -1 }, "queue size must not be negative"},
+		{"zero queue timeout", func(cfg *Config) { cfg.Proxy.MaxConcurrency = 10; cfg.Proxy.QueueTimeout = 0 }, "queue timeout must be positive"},
+		{"min above max", func(cfg *Config) {
+			cfg.Proxy.MaxConcurrency = 10
+			cfg.Proxy.AdaptiveConcurrency = true
+			cfg.Proxy.MinConcurrency = 20
+		}, "min concurrency must be between"},
+		{"zero latency target", func(cfg *Config) {
+			cfg.Proxy.MaxConcurrency = 10
+			cfg.Proxy.AdaptiveConcurrency = true
+			cfg.Proxy.LatencyTarget = 0
+		}, "latency target must be positive"},
+		{"valid adaptive", func(cfg *Config) {
+			cfg.Proxy.MaxConcurrency = 10
+			cfg.Proxy.AdaptiveConcurrency = true
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Proxy.Enabled = true
+			cfg.Proxy.Port = 80
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromEnv_TCPProxy(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_PROXY_ENABLED", "true")
+	os.Setenv("ZEROHALT_PROXY_MODE", "tcp")
+	os.Setenv("ZEROHALT_PROXY_PORT", "5432")
+	os.Setenv("ZEROHALT_PROXY_DRAIN_GRACE", "15s")
+	os.Setenv("ZEROHALT_PROXY_DRAIN_CLOSE_MODE", "reset")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, ProxyModeTCP, cfg.Proxy.Mode)
+	assert.Equal(t, 15*time.Second, cfg.Proxy.DrainGrace)
+	assert.Equal(t, proxy.CloseModeReset, cfg.Proxy.DrainCloseMode)
+}
+
+func TestLoadFromEnv_InvalidProxyDrainGrace(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_PROXY_DRAIN_GRACE", "a while")
+	defer os.Clearenv()
+
+	_, err := LoadFromEnv()
+	assert.Error(t, err)
+}
+
+func TestValidate_TCPProxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{"invalid mode", func(cfg *Config) { cfg.Proxy.Mode = "udp" }, "invalid proxy mode"},
+		{"negative grace", func(cfg *Config) { cfg.Proxy.DrainGrace = -1 * time.Second }, "drain grace period must not be negative"},
+		{"invalid close mode", func(cfg *Config) { cfg.Proxy.DrainCloseMode = "slam" }, "invalid proxy drain close mode"},
+		{"concurrency limit", func(cfg *Config) { cfg.Proxy.MaxConcurrency = 10 }, "only supported in http proxy mode"},
+		{"restart signal", func(cfg *Config) {
+			cfg.Signal.PassThroughSignals = []string{"SIGHUP"}
+			cfg.Signal.RestartSignal = "SIGUSR2"
+		}, "restart signal requires http proxy mode"},
+		{"valid", func(cfg *Config) {}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Proxy.Enabled = true
+			cfg.Proxy.Mode = ProxyModeTCP
+			cfg.Proxy.Port = 5432
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_SocketActivationWithoutShell(t *testing.T) {
+	original := activationShell
+	activationShell = "/nonexistent/sh"
+	defer func() { activationShell = original }()
+
+	cfg := DefaultConfig()
+	cfg.Activation.Enabled = true
+
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "socket activation runs the application through /nonexistent/sh, which is not available")
+
+	cfg.Activation.Enabled = false
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadFromEnv_SocketActivation(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_SOCKET_ACTIVATION", "true")
+	os.Setenv("ZEROHALT_APP_ADDITIONAL_PORTS", "9090")
+	os.Setenv("ZEROHALT_SOCKET_ACTIVATION_NAMES", "http,grpc")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.True(t, cfg.Activation.Enabled)
+	assert.Equal(t, []string{"http", "grpc"}, cfg.Activation.Names)
+}
+
+func TestValidate_SocketActivation(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		wantErr string
+	}{
+		{"default names", []string{}, ""},
+		{"one name per port", []string{"http", "grpc"}, ""},
+		{"too few names", []string{"http"}, "one name per app port"},
+		{"colon in name", []string{"http", "grpc:1"}, "invalid socket activation name"},
+		{"empty name", []string{"http", ""}, "invalid socket activation name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.App.AdditionalPorts = []uint16{9090}
+			cfg.Activation.Enabled = true
+			cfg.Activation.Names = tt.names
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromEnv_NotifyWatchdog(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_HEALTH_MODE", "notify")
+	os.Setenv("ZEROHALT_NOTIFY_WATCHDOG", "10s")
+	os.Setenv("ZEROHALT_NOTIFY_WATCHDOG_ACTION", "restart")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, HealthModeNotify, cfg.Health.Mode)
+	assert.Equal(t, 10*time.Second, cfg.Health.Watchdog)
+	assert.Equal(t, WatchdogActionRestart, cfg.Health.WatchdogAction)
+}
+
+func TestLoadFromEnv_InvalidNotifyWatchdog(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_NOTIFY_WATCHDOG", "soon")
+	defer os.Clearenv()
+
+	_, err := LoadFromEnv()
+	assert.ErrorContains(t, err, "invalid ZEROHALT_NOTIFY_WATCHDOG")
+}
+
+func TestValidate_NotifyWatchdog(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     HealthMode
+		watchdog time.Duration
+		action   WatchdogAction
+		wantErr  string
+	}{
+		{"notify without watchdog", HealthModeNotify, 0, WatchdogActionUnhealthy, ""},
+		{"notify with restart", HealthModeNotify, 10 * time.Second, WatchdogActionRestart, ""},
+		{"negative watchdog", HealthModeNotify, -time.Second, WatchdogActionUnhealthy, "must not be negative"},
+		{"invalid action", HealthModeNotify, time.Second, "reboot", "invalid notify watchdog action"},
+		{"watchdog outside notify mode", HealthModeStandalone, time.Second, WatchdogActionUnhealthy, "requires health mode notify"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Health.Mode = tt.mode
+			cfg.Health.Watchdog = tt.watchdog
+			cfg.Health.WatchdogAction = tt.action
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromEnv_PreStartHooks(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_HOOK_TIMEOUT", "2m")
+	os.Setenv("ZEROHALT_HOOK_RETRIES", "1")
+	os.Setenv("ZEROHALT_PRE_START_HOOK_1", "/app/migrate --up")
+	os.Setenv("ZEROHALT_PRE_START_HOOK_2", "/app/fetch-config")
+	os.Setenv("ZEROHALT_PRE_START_HOOK_2_TIMEOUT", "10s")
+	os.Setenv("ZEROHALT_PRE_START_HOOK_2_RETRIES", "3")
+	os.Setenv("ZEROHALT_PRE_START_HOOK_4", "/app/skipped")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+
+	expected := []HookConfig{
+		{Command: []string{"/app/migrate", "--up"}, Timeout: 2 * time.Minute, Retries: 1},
+		{Command: []string{"/app/fetch-config"}, Timeout: 10 * time.Second, Retries: 3},
+	}
+	assert.Equal(t, expected, cfg.Hooks.PreStart, "hooks after a gap in numbering are not read")
+}
+
+func TestLoadFromEnv_InvalidHookSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"default timeout", map[string]string{"ZEROHALT_HOOK_TIMEOUT": "later"}, "invalid ZEROHALT_HOOK_TIMEOUT"},
+		{"default retries", map[string]string{"ZEROHALT_HOOK_RETRIES": "many"}, "invalid ZEROHALT_HOOK_RETRIES"},
+		{"hook timeout", map[string]string{"ZEROHALT_PRE_START_HOOK_1": "true", "ZEROHALT_PRE_START_HOOK_1_TIMEOUT": "later"}, "invalid ZEROHALT_PRE_START_HOOK_1_TIMEOUT"},
+		{"hook retries", map[string]string{"ZEROHALT_PRE_START_HOOK_1": "true", "ZEROHALT_PRE_START_HOOK_1_RETRIES": "many"}, "invalid ZEROHALT_PRE_START_HOOK_1_RETRIES"},
+		{"zero timeout", map[string]string{"ZEROHALT_PRE_START_HOOK_1": "true", "ZEROHALT_PRE_START_HOOK_1_TIMEOUT": "0s"}, "pre-start hook 1 timeout must be positive"},
+		{"negative retries", map[string]string{"ZEROHALT_PRE_START_HOOK_1": "true", "ZEROHALT_PRE_START_HOOK_1_RETRIES": "-1"}, "pre-start hook 1 retries must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range tt.env {
+				os.Setenv(key, value)
+			}
+			defer os.Clearenv()
+
+			_, err := LoadFromEnv()
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromEnv_ShutdownHooks(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_PRE_DRAIN_HOOK_1", "PUT http://consul:8500/v1/agent/service/deregister/web")
+	os.Setenv("ZEROHALT_PRE_STOP_HOOK_1", "http://localhost:8080/flush")
+	os.Setenv("ZEROHALT_POST_STOP_HOOK_1", "/app/upload-report --final")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []HookConfig{{Method: "PUT", URL: "http://consul:8500/v1/agent/service/deregister/web", Timeout: 60 * time.Second}}, cfg.Hooks.PreDrain)
+	assert.Equal(t, []HookConfig{{Method: "POST", URL: "http://localhost:8080/flush", Timeout: 60 * time.Second}}, cfg.Hooks.PreStop)
+	assert.Equal(t, []HookConfig{{Command: []string{"/app/upload-report", "--final"}, Timeout: 60 * time.Second}}, cfg.Hooks.PostStop)
+}
+
+func TestValidate_HTTPHooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    HookConfig
+		wantErr string
+	}{
+		{"valid", HookConfig{Method: "POST", URL: "http://localhost/drain", Timeout: time.Second}, ""},
+		{"invalid method", HookConfig{Method: "FETCH", URL: "http://localhost/drain", Timeout: time.Second}, "invalid pre-drain hook 1 method: FETCH"},
+		{"invalid URL", HookConfig{Method: "POST", URL: "http://%zz", Timeout: time.Second}, "invalid pre-drain hook 1 URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Hooks.PreDrain = []HookConfig{tt.hook}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromEnv_DrainNotify(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_DRAIN_NOTIFY_SIGNAL", "SIGUSR1")
+	os.Setenv("ZEROHALT_DRAIN_NOTIFY_URL", "http://localhost:8080/drain")
+	os.Setenv("ZEROHALT_DRAIN_NOTIFY_FILE", "/tmp/draining")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "SIGUSR1", cfg.Shutdown.DrainNotifySignal)
+	assert.Equal(t, "http://localhost:8080/drain", cfg.Shutdown.DrainNotifyURL)
+	assert.Equal(t, "/tmp/draining", cfg.Shutdown.DrainNotifyFile)
+}
+
+func TestValidate_DrainNotify(t *testing.T) {
+	tests := []struct {
+		name        string
+		signal      string
+		signalToApp string
+		url         string
+		file        string
+		wantErr     string
+	}{
+		{"none", "", "", "", "", ""},
+		{"all channels", "SIGUSR1", "", "https://localhost/drain", "/tmp/draining", ""},
+		{"invalid signal", "SIGFOO", "", "", "", "invalid drain notify signal"},
+		{"same as shutdown signal", "SIGTERM", "", "", "", "must differ from the signal that stops the app"},
+		{"same as signal to app", "SIGQUIT", "SIGQUIT", "", "", "must differ from the signal that stops the app"},
+		{"non-http URL", "", "", "ftp://localhost/drain", "", "must be http or https"},
+		{"relative file", "", "", "", "draining", "must be an absolute path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Shutdown.DrainNotifySignal = tt.signal
+			cfg.Shutdown.SignalToApp = tt.signalToApp
+			cfg.Shutdown.DrainNotifyURL = tt.url
+			cfg.Shutdown.DrainNotifyFile = tt.file
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromEnv_DrainDelay(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_DRAIN_DELAY", "5s")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, cfg.Shutdown.DrainDelay)
+}
+
+func TestLoadFromEnv_InvalidDrainDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   string
+		wantErr string
+	}{
+		{"unparseable", "soon", "invalid ZEROHALT_DRAIN_DELAY"},
+		{"negative", "-1s", "drain delay must not be negative"},
+		{"not less than drain timeout", "60s", "drain delay must be less than drain timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv("ZEROHALT_DRAIN_DELAY", tt.delay)
+			defer os.Clearenv()
+
+			_, err := LoadFromEnv()
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromEnv_RepeatSignalAction(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"ignore", false},
+		{"expedite", false},
+		{"kill", false},
+		{"panic", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv("ZEROHALT_REPEAT_SIGNAL_ACTION", tt.value)
+			defer os.Clearenv()
+
+			cfg, err := LoadFromEnv()
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "invalid repeat signal action")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, shutdown.RepeatSignalAction(tt.value), cfg.Shutdown.RepeatSignalAction)
+		})
+	}
+}
+
+func TestLoadFromEnv_ShutdownBudget(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_SHUTDOWN_BUDGET", "30s")
+	os.Setenv("ZEROHALT_DRAIN_BUDGET_PERCENT", "60")
+	os.Setenv("ZEROHALT_DRAIN_BUDGET_MIN", "10s")
+	os.Setenv("ZEROHALT_STOP_BUDGET_MIN", "5s")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+
+	budget := cfg.ShutdownBudget()
+	assert.Equal(t, 30*time.Second, budget.Total)
+	assert.Equal(t, 60, budget.DrainPercent)
+	assert.Equal(t, 10*time.Second, budget.DrainMin)
+	assert.Equal(t, 5*time.Second, budget.StopMin)
+	assert.Empty(t, cfg.Warnings())
+}
+
+func TestLoadFromEnv_InvalidShutdownBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"unparseable budget", map[string]string{"ZEROHALT_SHUTDOWN_BUDGET": "soon"}, "invalid ZEROHALT_SHUTDOWN_BUDGET"},
+		{"negative budget", map[string]string{"ZEROHALT_SHUTDOWN_BUDGET": "-1s"}, "shutdown budget must not be negative"},
+		{"unparseable percent", map[string]string{"ZEROHALT_DRAIN_BUDGET_PERCENT": "most"}, "invalid ZEROHALT_DRAIN_BUDGET_PERCENT"},
+		{"percent above 100", map[string]string{"ZEROHALT_DRAIN_BUDGET_PERCENT": "120"}, "drain budget percent must be between 0 and 100"},
+		{"unparseable drain minimum", map[string]string{"ZEROHALT_DRAIN_BUDGET_MIN": "x"}, "invalid ZEROHALT_DRAIN_BUDGET_MIN"},
+		{"negative stop minimum", map[string]string{"ZEROHALT_STOP_BUDGET_MIN": "-1s"}, "budget minimums must not be negative"},
+		{
+			"drain delay not less than drain budget",
+			map[string]string{"ZEROHALT_SHUTDOWN_BUDGET": "10s", "ZEROHALT_DRAIN_DELAY": "8s"},
+			"drain delay must be less than drain budget",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range tt.env {
+				os.Setenv(key, value)
+			}
+			defer os.Clearenv()
+
+			_, err := LoadFromEnv()
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestConfig_Warnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(cfg *Config)
+		wantWarn string
+	}{
+		{
+			name:   "no budget",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "phases fit the budget",
+			modify: func(cfg *Config) {
+				cfg.Shutdown.Budget = 30 * time.Second
+				cfg.Shutdown.DrainDelay = 5 * time.Second
+			},
+		},
+		{
+			name: "minimums exceed the budget",
+			modify: func(cfg *Config) {
+				cfg.Shutdown.Budget = 30 * time.Second
+				cfg.Shutdown.DrainBudgetMin = 20 * time.Second
+				cfg.Shutdown.StopBudgetMin = 20 * time.Second
+			},
+			wantWarn: "budget minimums (40s) exceed the shutdown budget (30s)",
+		},
+		{
+			name: "drain hooks exceed the drain budget",
+			modify: func(cfg *Config) {
+				cfg.Shutdown.Budget = 30 * time.Second
+				cfg.Shutdown.DrainDelay = 10 * time.Second
+				cfg.Hooks.PreDrain = []HookConfig{{Command: []string{"true"}, Timeout: 10 * time.Second, Retries: 1}}
+			},
+			wantWarn: "(30s) exceed the drain budget (24s)",
+		},
+		{
+			name: "stop hooks exceed the stop budget",
+			modify: func(cfg *Config) {
+				cfg.Shutdown.Budget = 30 * time.Second
+				cfg.Hooks.PreStop = []HookConfig{{Command: []string{"true"}, Timeout: 2 * time.Second}}
+				cfg.Hooks.PostStop = []HookConfig{{Command: []string{"true"}, Timeout: 5 * time.Second}}
+			},
+			wantWarn: "pre-stop and post-stop hook timeouts (7s) exceed the stop budget (6s)",
+		},
+		{
+			name: "linger runs past the budget",
+			modify: func(cfg *Config) {
+				cfg.Shutdown.Budget = 30 * time.Second
+				cfg.Shutdown.Linger = 5 * time.Second
+			},
+			wantWarn: "shutdown linger (5s) runs after the shutdown budget (30s) is spent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+
+			warnings := cfg.Warnings()
+			if tt.wantWarn == "" {
+				assert.Empty(t, warnings)
+				return
+			}
+			assert.Len(t, warnings, 1)
+			assert.Contains(t, warnings[0], tt.wantWarn)
+		})
+	}
+}
+
+func TestLoadFromEnv_ShutdownTimeline(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_SHUTDOWN_TIMELINE", "false")
+	os.Setenv("ZEROHALT_TERMINATION_LOG_PATH", "/tmp/termination-log")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.False(t, cfg.Shutdown.Timeline)
+	assert.Equal(t, "/tmp/termination-log", cfg.Shutdown.TerminationLogPath)
+}
+
+func TestLoadFromEnv_RelativeTerminationLogPath(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_TERMINATION_LOG_PATH", "termination-log")
+	defer os.Clearenv()
+
+	_, err := LoadFromEnv()
+	assert.ErrorContains(t, err, "termination log path must be absolute")
+}
+
+func TestLoadFromEnv_ShutdownLinger(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ZEROHALT_SHUTDOWN_LINGER", "15s")
+	defer os.Clearenv()
+
+	cfg, err := LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Second, cfg.Shutdown.Linger)
+}
+
+func TestLoadFromEnv_InvalidShutdownLinger(t *testing.T) {
+	tests := []struct {
+		name    string
+		linger  string
+		wantErr string
+	}{
+		{"unparseable", "later", "invalid ZEROHALT_SHUTDOWN_LINGER"},
+		{"negative", "-1s", "shutdown linger must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv("ZEROHALT_SHUTDOWN_LINGER", tt.linger)
+			defer os.Clearenv()
+
+			_, err := LoadFromEnv()
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromEnv_PreStopPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"valid", map[string]string{"ZEROHALT_PRESTOP_PATH": "/prestop"}, ""},
+		{"relative", map[string]string{"ZEROHALT_PRESTOP_PATH": "prestop"}, "preStop path must start with /"},
+		{"same as health path", map[string]string{"ZEROHALT_PRESTOP_PATH": "/health"}, "conflicts with another endpoint"},
+		{
+			"same as metrics path on the health port",
+			map[string]string{"ZEROHALT_PRESTOP_PATH": "/metrics", "ZEROHALT_METRICS_ENABLED": "true"},
+			"conflicts with another endpoint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range tt.env {
+				os.Setenv(key, value)
+			}
+			defer os.Clearenv()
+
+			cfg, err := LoadFromEnv()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.env["ZEROHALT_PRESTOP_PATH"], cfg.Health.PreStopPath)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// EnableAdminEndpoint serves handler on path for POST requests that carry
+// token as a bearer token.
+func (s *Server) EnableAdminEndpoint(path string, token string, handler http.HandlerFunc) {
+	mux := s.server.Handler.(*http.ServeMux)
+	mux.Handle(path, requireAdminToken(token, handler))
+	slog.Info("Admin endpoint enabled", "path", path, "port", s.port)
+}
+
+// EnablePreStopEndpoint serves handler on path for GET and POST requests,
+// since Kubernetes preStop httpGet hooks can only send GET. When token is
+// set, requests must carry it as a bearer token.
+func (s *Server) EnablePreStopEndpoint(path string, token string, handler http.HandlerFunc) {
+	mux := s.server.Handler.(*http.ServeMux)
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			w.Header().Set("Allow", "GET, POST")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte(`{"status":"method not allowed"}`))
+			return
+		}
+
+		if token != "" && !hasAdminToken(r, token) {
+			rejectUnauthorized(w, r)
+			return
+		}
+
+		handler(w, r)
+	})
+	slog.Info("PreStop endpoint enabled", "path", path, "port", s.port, "token_required", token != "")
+}
+
+func requireAdminToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte(`{"status":"method not allowed"}`))
+			return
+		}
+
+		if !hasAdminToken(r, token) {
+			rejectUnauthorized(w, r)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func hasAdminToken(r *http.Request, token string) bool {
+	provided, hasBearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	tokenMatches := subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+	return token != "" && hasBearer && tokenMatches
+}
+
+func rejectUnauthorized(w http.ResponseWriter, r *http.Request) {
+	slog.Warn("Rejected unauthorized admin request", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+	w.WriteHeader(http.StatusUnauthorized)
+	w.Write([]byte(`{"status":"unauthorized"}`))
+}
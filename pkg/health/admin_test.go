@@ -0,0 +1,110 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_EnableAdminEndpoint(t *testing.T) {
+	s := NewServer(getAvailablePort(), "/health")
+
+	called := false
+	s.EnableAdminEndpoint("/admin/restart", "secret", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("POST", "/admin/restart", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, called)
+}
+
+func TestRequireAdminToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		method     string
+		auth       string
+		wantStatus int
+	}{
+		{"valid token", "secret", "POST", "Bearer secret", http.StatusOK},
+		{"wrong token", "secret", "POST", "Bearer guess", http.StatusUnauthorized},
+		{"missing header", "secret", "POST", "", http.StatusUnauthorized},
+		{"not a bearer token", "secret", "POST", "secret", http.StatusUnauthorized},
+		{"no token configured", "", "POST", "Bearer ", http.StatusUnauthorized},
+		{"wrong method", "secret", "GET", "Bearer secret", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := requireAdminToken(tt.token, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/admin/restart", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestServer_EnablePreStopEndpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		method     string
+		auth       string
+		wantStatus int
+	}{
+		{"get without token", "", "GET", "", http.StatusOK},
+		{"post without token", "", "POST", "", http.StatusOK},
+		{"valid token", "secret", "GET", "Bearer secret", http.StatusOK},
+		{"missing token", "secret", "GET", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "GET", "Bearer guess", http.StatusUnauthorized},
+		{"wrong method", "", "DELETE", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(getAvailablePort(), "/health")
+			s.EnablePreStopEndpoint("/prestop", tt.token, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/prestop", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			s.server.Handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
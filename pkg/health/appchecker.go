@@ -75,7 +75,7 @@ func (a *AppHealthChecker) Check() bool {
 	return false
 }
 
-func (a *AppHealthChecker) WaitForHealthy(startupTimeout time.Duration, checkInterval time.Duration) bool {
+func (a *AppHealthChecker) WaitForHealthy(ctx context.Context, startupTimeout time.Duration, checkInterval time.Duration) bool {
 	slog.Info("Waiting for application to become healthy", "url", a.healthURL, "timeout", startupTimeout)
 
 	deadline := time.Now().Add(startupTimeout)
@@ -96,6 +96,9 @@ func (a *AppHealthChecker) WaitForHealthy(startupTimeout time.Duration, checkInt
 		select {
 		case <-ticker.C:
 			continue
+		case <-ctx.Done():
+			slog.Warn("Stopped waiting for application health", "url", a.healthURL, "reason", ctx.Err())
+			return false
 		}
 	}
 }
@@ -15,6 +15,7 @@
 package health
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -120,7 +121,7 @@ func TestAppHealthChecker_WaitForHealthy(t *testing.T) {
 			defer server.Close()
 
 			checker := NewAppHealthChecker(server.URL, 1*time.Second)
-			got := checker.WaitForHealthy(tt.startupTimeout, tt.checkInterval)
+			got := checker.WaitForHealthy(context.Background(), tt.startupTimeout, tt.checkInterval)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -128,10 +129,21 @@ func TestAppHealthChecker_WaitForHealthy(t *testing.T) {
 
 func TestAppHealthChecker_WaitForHealthyWithInvalidURL(t *testing.T) {
 	checker := NewAppHealthChecker("http://invalid-host:9999/health", 1*time.Second)
-	got := checker.WaitForHealthy(1*time.Second, 100*time.Millisecond)
+	got := checker.WaitForHealthy(context.Background(), 1*time.Second, 100*time.Millisecond)
 	assert.False(t, got)
 }
 
+func TestAppHealthChecker_WaitForHealthyCancelled(t *testing.T) {
+	checker := NewAppHealthChecker("http://invalid-host:9999/health", 1*time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	got := checker.WaitForHealthy(ctx, 10*time.Second, 100*time.Millisecond)
+	assert.False(t, got)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
 func TestAppHealthChecker_Check_SetsMetricsWhenHealthy(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
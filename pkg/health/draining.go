@@ -0,0 +1,59 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+// DrainingServer is a Server that runs listeners when a shutdown moves it to
+// Draining and when a cancelled drain returns it to service, so other parts
+// of the service can follow the drain.
+type DrainingServer struct {
+	*Server
+	drainListeners  []func()
+	resumeListeners []func()
+}
+
+// OnDraining registers fn to run whenever the state becomes Draining.
+func (d *DrainingServer) OnDraining(fn func()) {
+	d.drainListeners = append(d.drainListeners, fn)
+}
+
+// OnResume registers fn to run whenever a cancelled drain returns the state
+// from Draining to service.
+func (d *DrainingServer) OnResume(fn func()) {
+	d.resumeListeners = append(d.resumeListeners, fn)
+}
+
+func (d *DrainingServer) SetState(state HealthState) {
+	d.Server.SetState(state)
+
+	isDraining := state == StateDraining && d.Server.GetState() == StateDraining
+	if !isDraining {
+		return
+	}
+
+	for _, fn := range d.drainListeners {
+		fn()
+	}
+}
+
+func (d *DrainingServer) ResumeFromDraining() bool {
+	if !d.Server.ResumeFromDraining() {
+		return false
+	}
+
+	for _, fn := range d.resumeListeners {
+		fn()
+	}
+	return true
+}
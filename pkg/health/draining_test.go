@@ -0,0 +1,73 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainingServer_SetState(t *testing.T) {
+	server := &DrainingServer{Server: NewServer(getAvailablePort(), "/health")}
+
+	server.SetState(StateHealthy)
+
+	assert.Equal(t, StateHealthy, server.GetState())
+}
+
+func TestDrainingServer_OnDraining(t *testing.T) {
+	server := &DrainingServer{Server: NewServer(getAvailablePort(), "/health")}
+
+	calls := 0
+	server.OnDraining(func() { calls++ })
+
+	server.SetState(StateHealthy)
+	assert.Equal(t, 0, calls)
+
+	server.SetState(StateDraining)
+	assert.Equal(t, 1, calls)
+
+	server.SetState(StateTerminating)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDrainingServer_OnDraining_BlockedTransition(t *testing.T) {
+	server := &DrainingServer{Server: NewServer(getAvailablePort(), "/health")}
+
+	calls := 0
+	server.OnDraining(func() { calls++ })
+
+	server.SetState(StateTerminating)
+	server.SetState(StateDraining)
+
+	assert.Equal(t, 0, calls, "listeners only run when the state actually becomes Draining")
+}
+
+func TestDrainingServer_OnResume(t *testing.T) {
+	server := &DrainingServer{Server: NewServer(getAvailablePort(), "/health")}
+
+	calls := 0
+	server.OnResume(func() { calls++ })
+
+	server.SetState(StateHealthy)
+	assert.False(t, server.ResumeFromDraining())
+	assert.Equal(t, 0, calls)
+
+	server.SetState(StateDraining)
+	assert.True(t, server.ResumeFromDraining())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, StateHealthy, server.GetState())
+}
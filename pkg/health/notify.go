@@ -0,0 +1,337 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+const (
+	notifyMaxMessageSize = 4096
+	watchdogPollInterval = 100 * time.Millisecond
+)
+
+// NotifySocket receives systemd sd_notify messages from the application.
+// READY=1 is the startup health signal, and WATCHDOG=1 keep-alives must
+// arrive within the watchdog timeout once one is set.
+type NotifySocket struct {
+	dir  string
+	conn *net.UnixConn
+	done chan struct{}
+
+	watchdogTimeout time.Duration
+
+	mu         sync.Mutex
+	ready      chan struct{}
+	isReady    bool
+	watchdog   time.Duration
+	lastPing   time.Time
+	missed     bool
+	stopping   bool
+	status     string
+	onStopping func()
+	onWatchdog func(missed bool)
+}
+
+// NewNotifySocket creates the socket the application reports to. A zero
+// watchdogTimeout leaves the watchdog off unless the application enables it
+// with WATCHDOG_USEC.
+func NewNotifySocket(watchdogTimeout time.Duration) (*NotifySocket, error) {
+	dir, err := os.MkdirTemp("", "zerohalt-notify-")
+	if err != nil {
+		return nil, err
+	}
+
+	addr := &net.UnixAddr{Name: filepath.Join(dir, "notify.sock"), Net: "unixgram"}
+	conn, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	n := &NotifySocket{
+		dir:             dir,
+		conn:            conn,
+		done:            make(chan struct{}),
+		watchdogTimeout: watchdogTimeout,
+	}
+	n.Reset()
+
+	go n.serve()
+	go n.watch()
+
+	return n, nil
+}
+
+func (n *NotifySocket) Path() string {
+	return n.conn.LocalAddr().String()
+}
+
+// Environment returns the variables that point the application at the socket.
+func (n *NotifySocket) Environment() []string {
+	env := []string{"NOTIFY_SOCKET=" + n.Path()}
+
+	if n.watchdogTimeout > 0 {
+		env = append(env, "WATCHDOG_USEC="+strconv.FormatInt(n.watchdogTimeout.Microseconds(), 10))
+	}
+
+	return env
+}
+
+// Reset forgets the state reported by the previous application instance. It
+// must be called before each instance starts so an early READY=1 is not lost.
+func (n *NotifySocket) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.ready = make(chan struct{})
+	n.isReady = false
+	n.watchdog = n.watchdogTimeout
+	n.lastPing = time.Now()
+	n.missed = false
+	n.stopping = false
+	n.status = ""
+}
+
+// OnStopping registers fn to run when the application reports STOPPING=1.
+func (n *NotifySocket) OnStopping(fn func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.onStopping = fn
+}
+
+// OnWatchdog registers fn to run when the application misses its watchdog
+// deadline, and again with missed set to false if keep-alives resume.
+func (n *NotifySocket) OnWatchdog(fn func(missed bool)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.onWatchdog = fn
+}
+
+func (n *NotifySocket) WaitForReady(ctx context.Context, timeout time.Duration) bool {
+	n.mu.Lock()
+	ready := n.ready
+	n.mu.Unlock()
+
+	select {
+	case <-ready:
+		return true
+	case <-time.After(timeout):
+		slog.Warn("Application did not report READY=1", "timeout", timeout)
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Healthy reports whether the application has reported ready, has not
+// reported stopping, and is keeping up with its watchdog.
+func (n *NotifySocket) Healthy() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.isReady && !n.stopping && !n.missed
+}
+
+func (n *NotifySocket) Close() error {
+	close(n.done)
+	err := n.conn.Close()
+	os.RemoveAll(n.dir)
+	return err
+}
+
+func (n *NotifySocket) serve() {
+	buf := make([]byte, notifyMaxMessageSize)
+
+	for {
+		size, _, err := n.conn.ReadFromUnix(buf)
+		if err != nil {
+			select {
+			case <-n.done:
+				return
+			default:
+			}
+			slog.Error("Error reading notify socket", "error", err)
+			continue
+		}
+
+		for _, line := range strings.Split(string(buf[:size]), "\n") {
+			n.handle(line)
+		}
+	}
+}
+
+func (n *NotifySocket) handle(line string) {
+	key, value, found := strings.Cut(line, "=")
+	if !found {
+		return
+	}
+
+	switch key {
+	case "READY":
+		metrics.NotifyMessages.WithLabelValues("ready").Inc()
+		n.markReady(value)
+	case "STOPPING":
+		metrics.NotifyMessages.WithLabelValues("stopping").Inc()
+		n.markStopping(value)
+	case "STATUS":
+		metrics.NotifyMessages.WithLabelValues("status").Inc()
+		n.setStatus(value)
+	case "WATCHDOG":
+		metrics.NotifyMessages.WithLabelValues("watchdog").Inc()
+		n.ping(value)
+	case "WATCHDOG_USEC":
+		metrics.NotifyMessages.WithLabelValues("watchdog").Inc()
+		n.setWatchdog(value)
+	default:
+		slog.Debug("Ignoring notify message", "key", key)
+	}
+}
+
+func (n *NotifySocket) markReady(value string) {
+	if value != "1" {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.isReady {
+		return
+	}
+
+	n.isReady = true
+	close(n.ready)
+	slog.Info("Application reported ready")
+}
+
+func (n *NotifySocket) markStopping(value string) {
+	if value != "1" {
+		return
+	}
+
+	n.mu.Lock()
+	n.stopping = true
+	fn := n.onStopping
+	n.mu.Unlock()
+
+	slog.Info("Application reported stopping")
+	if fn != nil {
+		fn()
+	}
+}
+
+func (n *NotifySocket) setStatus(value string) {
+	n.mu.Lock()
+	changed := n.status != value
+	n.status = value
+	n.mu.Unlock()
+
+	if changed {
+		slog.Info("Application status", "status", value)
+	}
+}
+
+func (n *NotifySocket) ping(value string) {
+	if value == "trigger" {
+		slog.Warn("Application triggered watchdog failure")
+		n.miss()
+		return
+	}
+
+	if value != "1" {
+		return
+	}
+
+	n.mu.Lock()
+	n.lastPing = time.Now()
+	recovered := n.missed
+	n.missed = false
+	fn := n.onWatchdog
+	n.mu.Unlock()
+
+	if recovered {
+		slog.Info("Application watchdog keep-alives resumed")
+		if fn != nil {
+			fn(false)
+		}
+	}
+}
+
+func (n *NotifySocket) setWatchdog(value string) {
+	usec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || usec < 0 {
+		slog.Warn("Ignoring invalid WATCHDOG_USEC", "value", value)
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.watchdog = time.Duration(usec) * time.Microsecond
+	n.lastPing = time.Now()
+	slog.Info("Application set watchdog timeout", "timeout", n.watchdog)
+}
+
+func (n *NotifySocket) watch() {
+	ticker := time.NewTicker(watchdogPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.done:
+			return
+		case <-ticker.C:
+			if n.deadlineExceeded() {
+				slog.Warn("Application missed watchdog deadline")
+				n.miss()
+			}
+		}
+	}
+}
+
+func (n *NotifySocket) deadlineExceeded() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	isEnabled := n.watchdog > 0
+	return isEnabled && !n.missed && time.Since(n.lastPing) > n.watchdog
+}
+
+func (n *NotifySocket) miss() {
+	n.mu.Lock()
+	if n.missed {
+		n.mu.Unlock()
+		return
+	}
+	n.missed = true
+	fn := n.onWatchdog
+	n.mu.Unlock()
+
+	metrics.NotifyWatchdogMissed.Inc()
+	if fn != nil {
+		fn(true)
+	}
+}
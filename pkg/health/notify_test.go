@@ -0,0 +1,227 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sendNotify(t *testing.T, n *NotifySocket, message string) {
+	t.Helper()
+
+	conn, err := net.Dial("unixgram", n.Path())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(message))
+	assert.NoError(t, err)
+}
+
+func TestNotifySocket_Environment(t *testing.T) {
+	n, err := NewNotifySocket(2 * time.Second)
+	assert.NoError(t, err)
+	defer n.Close()
+
+	env := n.Environment()
+
+	assert.Contains(t, env, "NOTIFY_SOCKET="+n.Path())
+	assert.Contains(t, env, "WATCHDOG_USEC=2000000")
+}
+
+func TestNotifySocket_Ready(t *testing.T) {
+	n, err := NewNotifySocket(0)
+	assert.NoError(t, err)
+	defer n.Close()
+
+	assert.False(t, n.WaitForReady(context.Background(), 50*time.Millisecond))
+
+	sendNotify(t, n, "STATUS=warming up\nREADY=1")
+
+	assert.True(t, n.WaitForReady(context.Background(), time.Second))
+}
+
+func TestNotifySocket_ResetForgetsReady(t *testing.T) {
+	n, err := NewNotifySocket(0)
+	assert.NoError(t, err)
+	defer n.Close()
+
+	sendNotify(t, n, "READY=1")
+	assert.True(t, n.WaitForReady(context.Background(), time.Second))
+
+	n.Reset()
+
+	assert.False(t, n.WaitForReady(context.Background(), 50*time.Millisecond), "a new instance must report ready again")
+}
+
+func TestNotifySocket_Stopping(t *testing.T) {
+	n, err := NewNotifySocket(0)
+	assert.NoError(t, err)
+	defer n.Close()
+
+	stopping := make(chan struct{})
+	n.OnStopping(func() { close(stopping) })
+
+	sendNotify(t, n, "STOPPING=1")
+
+	select {
+	case <-stopping:
+	case <-time.After(time.Second):
+		t.Fatal("STOPPING=1 should run the stopping handler")
+	}
+}
+
+func TestNotifySocket_WatchdogMissedAndRecovered(t *testing.T) {
+	n, err := NewNotifySocket(200 * time.Millisecond)
+	assert.NoError(t, err)
+	defer n.Close()
+
+	events := make(chan bool, 2)
+	n.OnWatchdog(func(missed bool) { events <- missed })
+
+	select {
+	case missed := <-events:
+		assert.True(t, missed)
+	case <-time.After(2 * time.Second):
+		t.Fatal("watchdog deadline should be reported as missed")
+	}
+
+	sendNotify(t, n, "WATCHDOG=1")
+
+	select {
+	case missed := <-events:
+		assert.False(t, missed)
+	case <-time.After(time.Second):
+		t.Fatal("a keep-alive after a miss should be reported as recovered")
+	}
+}
+
+func TestNotifySocket_WatchdogKeepAlive(t *testing.T) {
+	n, err := NewNotifySocket(300 * time.Millisecond)
+	assert.NoError(t, err)
+	defer n.Close()
+
+	var missed atomic.Bool
+	n.OnWatchdog(func(m bool) { missed.Store(m) })
+
+	for i := 0; i < 6; i++ {
+		sendNotify(t, n, "WATCHDOG=1")
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	assert.False(t, missed.Load(), "regular keep-alives should keep the watchdog satisfied")
+}
+
+func TestNotifySocket_WatchdogTrigger(t *testing.T) {
+	n, err := NewNotifySocket(0)
+	assert.NoError(t, err)
+	defer n.Close()
+
+	events := make(chan bool, 1)
+	n.OnWatchdog(func(missed bool) { events <- missed })
+
+	sendNotify(t, n, "WATCHDOG=trigger")
+
+	select {
+	case missed := <-events:
+		assert.True(t, missed)
+	case <-time.After(time.Second):
+		t.Fatal("WATCHDOG=trigger should be reported as missed")
+	}
+}
+
+func TestNotifySocket_AppSetsWatchdog(t *testing.T) {
+	n, err := NewNotifySocket(0)
+	assert.NoError(t, err)
+	defer n.Close()
+
+	events := make(chan bool, 1)
+	n.OnWatchdog(func(missed bool) { events <- missed })
+
+	sendNotify(t, n, "WATCHDOG_USEC=200000")
+
+	select {
+	case missed := <-events:
+		assert.True(t, missed)
+	case <-time.After(2 * time.Second):
+		t.Fatal("a watchdog enabled by the application should be enforced")
+	}
+}
+
+func TestNotifySocket_CloseRemovesSocket(t *testing.T) {
+	n, err := NewNotifySocket(0)
+	assert.NoError(t, err)
+
+	path := n.Path()
+	assert.NoError(t, n.Close())
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestServer_WaitForAppHealthy_Notify(t *testing.T) {
+	n, err := NewNotifySocket(0)
+	assert.NoError(t, err)
+	defer n.Close()
+
+	s := NewServerWithNotify(getAvailablePort(), "/health", n)
+
+	assert.False(t, s.WaitForAppHealthy(context.Background(), 50*time.Millisecond, 10*time.Millisecond))
+
+	sendNotify(t, n, "READY=1")
+
+	assert.True(t, s.WaitForAppHealthy(context.Background(), time.Second, 10*time.Millisecond))
+}
+
+func TestServer_ResumeFromDraining_Notify(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		wantState HealthState
+	}{
+		{"ready", "READY=1", StateHealthy},
+		{"not ready", "", StateUnhealthy},
+		{"stopping", "READY=1\nSTOPPING=1", StateUnhealthy},
+		{"watchdog missed", "READY=1\nWATCHDOG=trigger", StateUnhealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewNotifySocket(0)
+			assert.NoError(t, err)
+			defer n.Close()
+
+			s := NewServerWithNotify(getAvailablePort(), "/health", n)
+			s.SetState(StateHealthy)
+			s.SetState(StateDraining)
+
+			if tt.message != "" {
+				sendNotify(t, n, tt.message)
+				assert.True(t, n.WaitForReady(context.Background(), time.Second))
+			}
+			wantHealthy := tt.wantState == StateHealthy
+			assert.Eventually(t, func() bool { return n.Healthy() == wantHealthy }, time.Second, 10*time.Millisecond)
+
+			assert.True(t, s.ResumeFromDraining())
+			assert.Equal(t, tt.wantState, s.GetState())
+		})
+	}
+}
@@ -24,12 +24,17 @@ import (
 	"github.com/jpasei/zerohalt/pkg/metrics"
 )
 
+// ServerShutdownTimeout bounds how long in-flight requests may hold up a
+// server's shutdown once the service is exiting.
+const ServerShutdownTimeout = 5 * time.Second
+
 type Server struct {
 	port       uint16
 	path       string
 	state      *State
 	server     *http.Server
 	appChecker *AppHealthChecker
+	notify     *NotifySocket
 }
 
 func NewServer(port uint16, path string) *Server {
@@ -55,6 +60,14 @@ func NewServerWithAppChecker(port uint16, path string, appChecker *AppHealthChec
 	return s
 }
 
+// NewServerWithNotify creates a server that treats READY=1 on notify as the
+// application's startup health signal.
+func NewServerWithNotify(port uint16, path string, notify *NotifySocket) *Server {
+	s := NewServer(port, path)
+	s.notify = notify
+	return s
+}
+
 // EnableMetrics adds the metrics endpoint to the health server
 func (s *Server) EnableMetrics(metricsPath string) {
 	mux := s.server.Handler.(*http.ServeMux)
@@ -83,13 +96,41 @@ func (s *Server) GetState() HealthState {
 	return s.state.Get()
 }
 
-func (s *Server) WaitForAppHealthy(startupTimeout time.Duration, checkInterval time.Duration) bool {
+// ResumeFromDraining returns a draining server to service: Healthy if the
+// application passes a fresh health check, or in notify mode is still ready
+// and keeping up with its watchdog, Unhealthy otherwise. It reports false if
+// the server was not draining.
+func (s *Server) ResumeFromDraining() bool {
+	target := StateHealthy
+	if s.appChecker != nil && !s.appChecker.Check() {
+		target = StateUnhealthy
+	}
+	if s.notify != nil && !s.notify.Healthy() {
+		target = StateUnhealthy
+	}
+
+	if !s.state.ResumeFromDraining(target) {
+		return false
+	}
+
+	metrics.HealthApp.Set(float64(target))
+	slog.Info("Health check back in service", "state", target.String())
+	return true
+}
+
+// WaitForAppHealthy blocks until the application reports healthy, the
+// startup timeout expires, or ctx is cancelled.
+func (s *Server) WaitForAppHealthy(ctx context.Context, startupTimeout time.Duration, checkInterval time.Duration) bool {
+	if s.notify != nil {
+		return s.notify.WaitForReady(ctx, startupTimeout)
+	}
+
 	if s.appChecker == nil {
 		slog.Debug("No app health checker configured, skipping app health wait")
 		return true
 	}
 
-	return s.appChecker.WaitForHealthy(startupTimeout, checkInterval)
+	return s.appChecker.WaitForHealthy(ctx, startupTimeout, checkInterval)
 }
 
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
@@ -347,7 +347,7 @@ func TestServer_WaitForAppHealthy(t *testing.T) {
 			appChecker := NewAppHealthChecker(appServer.URL, 1*time.Second)
 			s := NewServerWithAppChecker(port, "/health", appChecker)
 
-			got := s.WaitForAppHealthy(tt.startupTimeout, tt.checkInterval)
+			got := s.WaitForAppHealthy(context.Background(), tt.startupTimeout, tt.checkInterval)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -357,7 +357,7 @@ func TestServer_WaitForAppHealthy_NoAppChecker(t *testing.T) {
 	port := getAvailablePort()
 	s := NewServer(port, "/health")
 
-	got := s.WaitForAppHealthy(1*time.Second, 100*time.Millisecond)
+	got := s.WaitForAppHealthy(context.Background(), 1*time.Second, 100*time.Millisecond)
 	assert.True(t, got)
 }
 
@@ -413,3 +413,40 @@ func TestServer_AppDependentMode_RealIntegration_AppDown(t *testing.T) {
 
 	s.Shutdown(ctx)
 }
+
+func TestServer_ResumeFromDraining_RechecksApp(t *testing.T) {
+	tests := []struct {
+		name       string
+		appStatus  int
+		wantState  HealthState
+		wantResume bool
+	}{
+		{"healthy app", http.StatusOK, StateHealthy, true},
+		{"unhealthy app", http.StatusServiceUnavailable, StateUnhealthy, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.appStatus)
+			}))
+			defer appServer.Close()
+
+			appChecker := NewAppHealthChecker(appServer.URL, 5*time.Second)
+			s := NewServerWithAppChecker(getAvailablePort(), "/health", appChecker)
+			s.SetState(StateHealthy)
+			s.SetState(StateDraining)
+
+			assert.Equal(t, tt.wantResume, s.ResumeFromDraining())
+			assert.Equal(t, tt.wantState, s.GetState())
+		})
+	}
+}
+
+func TestServer_ResumeFromDraining_NotDraining(t *testing.T) {
+	s := NewServer(getAvailablePort(), "/health")
+	s.SetState(StateHealthy)
+
+	assert.False(t, s.ResumeFromDraining())
+	assert.Equal(t, StateHealthy, s.GetState())
+}
@@ -86,6 +86,23 @@ func (s *State) Set(state HealthState) {
 	slog.Debug("State transition successful", "new_state", state.String())
 }
 
+// ResumeFromDraining moves a draining state back to target, the one way out
+// of Draining other than Terminating. It reports false if the state was not
+// Draining.
+func (s *State) ResumeFromDraining(target HealthState) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != StateDraining {
+		return false
+	}
+
+	s.current = target
+	metrics.State.Set(float64(target))
+	slog.Debug("Resumed from draining", "new_state", target.String())
+	return true
+}
+
 func (s *State) Get() HealthState {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -154,3 +154,18 @@ func TestState_CanTransitionFromDrainingToTerminating(t *testing.T) {
 	s.Set(StateTerminating)
 	assert.Equal(t, StateTerminating, s.Get())
 }
+
+func TestState_ResumeFromDraining(t *testing.T) {
+	s := NewState()
+	s.Set(StateHealthy)
+
+	assert.False(t, s.ResumeFromDraining(StateHealthy), "only a draining state can resume")
+
+	s.Set(StateDraining)
+	assert.True(t, s.ResumeFromDraining(StateUnhealthy))
+	assert.Equal(t, StateUnhealthy, s.Get())
+
+	s.Set(StateTerminating)
+	assert.False(t, s.ResumeFromDraining(StateHealthy))
+	assert.Equal(t, StateTerminating, s.Get())
+}
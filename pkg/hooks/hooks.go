@@ -0,0 +1,217 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+// retryDelay is the pause between attempts of a failing hook.
+var retryDelay = time.Second
+
+// outputWaitDelay bounds how long a finished hook's output is read when a
+// process it spawned still holds stdout or stderr open.
+const outputWaitDelay = time.Second
+
+// Hook is a command or HTTP call zerohalt makes at a defined point in the
+// application's lifecycle. A hook with a URL is an HTTP call.
+type Hook struct {
+	Name    string
+	Command []string
+	Method  string
+	URL     string
+	Timeout time.Duration
+	Retries int
+}
+
+// Run executes the hook, retrying up to Retries times. Each attempt is
+// bounded by Timeout and by ctx.
+func (h Hook) Run(ctx context.Context) error {
+	var err error
+
+	for attempt := 0; attempt <= h.Retries; attempt++ {
+		if attempt > 0 {
+			slog.Warn("Retrying hook", "hook", h.Name, "attempt", attempt+1, "error", err)
+
+			select {
+			case <-time.After(retryDelay):
+			case <-ctx.Done():
+				return fmt.Errorf("hook %s: %w", h.Name, ctx.Err())
+			}
+		}
+
+		err = h.runOnce(ctx)
+		if err == nil {
+			metrics.HookRuns.WithLabelValues(h.Name, "success").Inc()
+			return nil
+		}
+	}
+
+	metrics.HookRuns.WithLabelValues(h.Name, "failed").Inc()
+	return fmt.Errorf("hook %s failed after %d attempts: %w", h.Name, h.Retries+1, err)
+}
+
+func (h Hook) runOnce(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	var err error
+	if h.URL != "" {
+		err = h.call(attemptCtx)
+	} else {
+		err = h.exec(attemptCtx)
+	}
+
+	isParentDone := ctx.Err() != nil
+	isTimedOut := attemptCtx.Err() == context.DeadlineExceeded
+	switch {
+	case isParentDone:
+		return fmt.Errorf("interrupted after %s: %w", time.Since(start).Round(time.Millisecond), ctx.Err())
+	case isTimedOut:
+		return fmt.Errorf("timed out after %s", h.Timeout)
+	case err != nil:
+		return err
+	}
+
+	slog.Info("Hook completed", "hook", h.Name, "duration", time.Since(start))
+	return nil
+}
+
+func (h Hook) call(ctx context.Context) error {
+	method := h.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	slog.Info("Running hook", "hook", h.Name, "method", method, "url", h.URL)
+
+	req, err := http.NewRequestWithContext(ctx, method, h.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	isSuccess := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !isSuccess {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (h Hook) exec(ctx context.Context) error {
+	if len(h.Command) == 0 {
+		return fmt.Errorf("no command configured")
+	}
+
+	slog.Info("Running hook", "hook", h.Name, "command", h.Command)
+
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	stdout := newPrefixWriter(os.Stdout, h.Name)
+	stderr := newPrefixWriter(os.Stderr, h.Name)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = outputWaitDelay
+
+	err := cmd.Run()
+	stdout.Flush()
+	stderr.Flush()
+
+	return err
+}
+
+// RunAll runs hooks in order and stops at the first failure.
+func RunAll(ctx context.Context, hooks []Hook) error {
+	for _, hook := range hooks {
+		if err := hook.Run(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunEach runs every hook in order, logging failures instead of stopping.
+// Shutdown uses it so one broken hook cannot block the rest of the sequence.
+func RunEach(ctx context.Context, hooks []Hook) {
+	for _, hook := range hooks {
+		if err := hook.Run(ctx); err != nil {
+			slog.Warn("Hook failed, continuing", "error", err)
+		}
+	}
+}
+
+// prefixWriter tags every line a hook writes with the hook name so its
+// output can be told apart from the application's.
+type prefixWriter struct {
+	out     io.Writer
+	prefix  string
+	pending []byte
+}
+
+func newPrefixWriter(out io.Writer, name string) *prefixWriter {
+	return &prefixWriter{
+		out:    out,
+		prefix: "[" + name + "] ",
+	}
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.pending = append(p.pending, data...)
+
+	for {
+		newline := bytes.IndexByte(p.pending, '\n')
+		if newline < 0 {
+			return len(data), nil
+		}
+
+		if _, err := fmt.Fprintf(p.out, "%s%s\n", p.prefix, p.pending[:newline]); err != nil {
+			return 0, err
+		}
+		p.pending = p.pending[newline+1:]
+	}
+}
+
+// Flush writes a trailing line that did not end in a newline.
+func (p *prefixWriter) Flush() {
+	if len(p.pending) == 0 {
+		return
+	}
+
+	fmt.Fprintf(p.out, "%s%s\n", p.prefix, p.pending)
+	p.pending = nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	retryDelay = 10 * time.Millisecond
+}
+
+func TestHook_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		wantErr string
+	}{
+		{"success", []string{"true"}, ""},
+		{"failure", []string{"false"}, "hook test failed after 1 attempts"},
+		{"no command", []string{}, "no command configured"},
+		{"missing binary", []string{"/nonexistent/command"}, "hook test failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := Hook{Name: "test", Command: tt.command, Timeout: 5 * time.Second}
+
+			err := hook.Run(context.Background())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestHook_Run_Timeout(t *testing.T) {
+	hook := Hook{Name: "slow", Command: []string{"sleep", "10"}, Timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	err := hook.Run(context.Background())
+
+	assert.ErrorContains(t, err, "timed out after 100ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestHook_Run_Retries(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "attempted")
+
+	// Fails on the first attempt and succeeds on the second.
+	script := `test -e "$0" || { touch "$0"; exit 1; }`
+	hook := Hook{Name: "flaky", Command: []string{"sh", "-c", script, marker}, Timeout: 5 * time.Second, Retries: 1}
+
+	err := hook.Run(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestHook_Run_RetriesExhausted(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "attempts")
+
+	script := `echo x >> "$0"; exit 1`
+	hook := Hook{Name: "broken", Command: []string{"sh", "-c", script, counter}, Timeout: 5 * time.Second, Retries: 2}
+
+	err := hook.Run(context.Background())
+	assert.ErrorContains(t, err, "hook broken failed after 3 attempts")
+
+	attempts, _ := os.ReadFile(counter)
+	assert.Equal(t, "x\nx\nx\n", string(attempts))
+}
+
+func TestRunAll_FailFast(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+
+	hooks := []Hook{
+		{Name: "first", Command: []string{"false"}, Timeout: 5 * time.Second},
+		{Name: "second", Command: []string{"touch", marker}, Timeout: 5 * time.Second},
+	}
+
+	err := RunAll(context.Background(), hooks)
+	assert.ErrorContains(t, err, "hook first failed")
+
+	_, statErr := os.Stat(marker)
+	assert.True(t, os.IsNotExist(statErr), "hooks after a failure should not run")
+}
+
+func TestRunAll_InOrder(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "order")
+
+	hooks := []Hook{
+		{Name: "first", Command: []string{"sh", "-c", `echo 1 >> "$0"`, log}, Timeout: 5 * time.Second},
+		{Name: "second", Command: []string{"sh", "-c", `echo 2 >> "$0"`, log}, Timeout: 5 * time.Second},
+	}
+
+	err := RunAll(context.Background(), hooks)
+	assert.NoError(t, err)
+
+	order, _ := os.ReadFile(log)
+	assert.Equal(t, "1\n2\n", string(order))
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := newPrefixWriter(&out, "pre-start-1")
+
+	writer.Write([]byte("first line\nsecond "))
+	writer.Write([]byte("line\nno newline"))
+	writer.Flush()
+
+	assert.Equal(t, "[pre-start-1] first line\n[pre-start-1] second line\n[pre-start-1] no newline\n", out.String())
+}
+
+func TestHook_Run_HTTP(t *testing.T) {
+	var method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook := Hook{Name: "deregister", URL: server.URL + "/ok", Timeout: time.Second}
+	assert.NoError(t, hook.Run(context.Background()))
+	assert.Equal(t, http.MethodPost, method, "HTTP hooks POST by default")
+
+	hook = Hook{Name: "deregister", Method: http.MethodPut, URL: server.URL + "/ok", Timeout: time.Second}
+	assert.NoError(t, hook.Run(context.Background()))
+	assert.Equal(t, http.MethodPut, method)
+
+	hook = Hook{Name: "deregister", URL: server.URL + "/fail", Timeout: time.Second}
+	assert.ErrorContains(t, hook.Run(context.Background()), "unexpected status 500")
+}
+
+func TestHook_Run_ParentDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	hook := Hook{Name: "slow", Command: []string{"sleep", "10"}, Timeout: time.Minute, Retries: 3}
+
+	start := time.Now()
+	err := hook.Run(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "the caller's deadline caps the hook and its retries")
+}
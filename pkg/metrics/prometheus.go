@@ -44,12 +44,89 @@ var (
 		Help: "Time since application started",
 	})
 
+	AppRestarts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "zerohalt_app_restarts_total",
+			Help: "Application restarts by result (success, failed, cancelled)",
+		},
+		[]string{"result"},
+	)
+
+	NotifyMessages = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "zerohalt_notify_messages_total",
+			Help: "sd_notify messages received from the application by type (ready, stopping, status, watchdog)",
+		},
+		[]string{"type"},
+	)
+
+	NotifyWatchdogMissed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "zerohalt_notify_watchdog_missed_total",
+		Help: "Watchdog deadlines missed by the application",
+	})
+
+	HookRuns = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "zerohalt_hook_runs_total",
+			Help: "Lifecycle hook runs by hook and result (success, failed)",
+		},
+		[]string{"hook", "result"},
+	)
+
 	// Connection Metrics
 	ActiveConnections = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name: "zerohalt_active_connections",
-		Help: "Current active connections on monitored ports",
+		Help: "Current active connections on monitored ports and Unix sockets",
 	})
 
+	ActiveUnixConnections = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "zerohalt_active_unix_connections",
+			Help: "Current connected stream sockets on monitored Unix socket paths",
+		},
+		[]string{"path"},
+	)
+
+	ActiveUDPFlows = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "zerohalt_active_udp_flows",
+			Help: "UDP sockets considered active on monitored UDP ports",
+		},
+		[]string{"port"},
+	)
+
+	ConnectionsByState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "zerohalt_connections",
+			Help: "Sockets on monitored ports by local port and TCP state",
+		},
+		[]string{"port", "state"},
+	)
+
+	ListenBacklog = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "zerohalt_listen_backlog",
+			Help: "Connections waiting to be accepted on a monitored listening port",
+		},
+		[]string{"port"},
+	)
+
+	ListenBacklogLimit = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "zerohalt_listen_backlog_limit",
+			Help: "Accept backlog limit of a monitored listening port",
+		},
+		[]string{"port"},
+	)
+
+	MonitoredPorts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "zerohalt_monitored_ports",
+			Help: "Ports monitored for active connections (1 per port)",
+		},
+		[]string{"port", "source"},
+	)
+
 	DrainPhaseActive = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name: "zerohalt_drain_phase_active",
 		Help: "1 if currently draining, 0 otherwise",
@@ -60,6 +137,116 @@ var (
 		Help: "Time spent draining connections",
 	})
 
+	ConnectionsForceClosed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "zerohalt_connections_force_closed_total",
+		Help: "Connections force closed during drain",
+	})
+
+	DrainNotifications = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "zerohalt_drain_notifications_total",
+			Help: "Drain notifications sent to the application by channel (signal, http, file) and result (success, failed)",
+		},
+		[]string{"channel", "result"},
+	)
+
+	DrainCancellations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "zerohalt_drain_cancellations_total",
+			Help: "Requests to cancel a drain by source (admin, signal) and result (cancelled, rejected)",
+		},
+		[]string{"source", "result"},
+	)
+
+	// Proxy Metrics
+	ProxyRequests = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "zerohalt_proxy_requests_total",
+		Help: "Requests received by the reverse proxy",
+	})
+
+	ProxyInFlightRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "zerohalt_proxy_in_flight_requests",
+		Help: "Requests currently being forwarded to the application",
+	})
+
+	ProxyHeldRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "zerohalt_proxy_held_requests",
+		Help: "Requests held by the proxy while the application restarts",
+	})
+
+	ProxyHoldRejected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "zerohalt_proxy_hold_rejected_total",
+			Help: "Held requests rejected during a restart by reason (queue_full, timeout)",
+		},
+		[]string{"reason"},
+	)
+
+	ProxyShed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "zerohalt_proxy_shed_total",
+			Help: "Requests rejected by the concurrency limiter by reason (queue_full, timeout)",
+		},
+		[]string{"reason"},
+	)
+
+	ProxyQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "zerohalt_proxy_queue_depth",
+		Help: "Requests waiting for a concurrency slot",
+	})
+
+	ProxyConcurrencyLimit = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "zerohalt_proxy_concurrency_limit",
+		Help: "Current limit on concurrent requests forwarded to the application",
+	})
+
+	// TCP Proxy Metrics
+	TCPProxyConnections = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "zerohalt_tcp_proxy_connections_total",
+		Help: "Connections accepted by the TCP proxy",
+	})
+
+	TCPProxyActiveConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "zerohalt_tcp_proxy_active_connections",
+		Help: "Open connections through the TCP proxy",
+	})
+
+	TCPProxyUpstreamErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "zerohalt_tcp_proxy_upstream_errors_total",
+		Help: "Connections the TCP proxy could not forward to the application",
+	})
+
+	TCPProxyBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "zerohalt_tcp_proxy_bytes_total",
+			Help: "Bytes forwarded by the TCP proxy by direction (to_app, to_client)",
+		},
+		[]string{"direction"},
+	)
+
+	TCPProxyConnectionBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "zerohalt_tcp_proxy_connection_bytes",
+			Help:    "Bytes forwarded per TCP proxy connection by direction (to_app, to_client)",
+			Buckets: prometheus.ExponentialBuckets(64, 8, 8),
+		},
+		[]string{"direction"},
+	)
+
+	TCPProxyConnectionDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "zerohalt_tcp_proxy_connection_duration_seconds",
+		Help:    "Lifetime of TCP proxy connections",
+		Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+	})
+
+	TCPProxyDrainClosed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "zerohalt_tcp_proxy_drain_closed_total",
+			Help: "TCP proxy connections closed after the drain grace period by mode (half-close, reset)",
+		},
+		[]string{"mode"},
+	)
+
 	// Health Check Metrics
 	HealthRequests = prometheus.NewCounter(prometheus.CounterOpts{
 		Name: "zerohalt_health_requests_total",
@@ -99,9 +286,36 @@ func init() {
 	registry.MustRegister(State)
 	registry.MustRegister(Uptime)
 	registry.MustRegister(AppUptime)
+	registry.MustRegister(AppRestarts)
+	registry.MustRegister(NotifyMessages)
+	registry.MustRegister(NotifyWatchdogMissed)
+	registry.MustRegister(HookRuns)
 	registry.MustRegister(ActiveConnections)
+	registry.MustRegister(ActiveUnixConnections)
+	registry.MustRegister(ActiveUDPFlows)
+	registry.MustRegister(ConnectionsByState)
+	registry.MustRegister(ListenBacklog)
+	registry.MustRegister(ListenBacklogLimit)
+	registry.MustRegister(MonitoredPorts)
 	registry.MustRegister(DrainPhaseActive)
 	registry.MustRegister(DrainDuration)
+	registry.MustRegister(ConnectionsForceClosed)
+	registry.MustRegister(DrainNotifications)
+	registry.MustRegister(DrainCancellations)
+	registry.MustRegister(ProxyRequests)
+	registry.MustRegister(ProxyInFlightRequests)
+	registry.MustRegister(ProxyHeldRequests)
+	registry.MustRegister(ProxyHoldRejected)
+	registry.MustRegister(ProxyShed)
+	registry.MustRegister(ProxyQueueDepth)
+	registry.MustRegister(ProxyConcurrencyLimit)
+	registry.MustRegister(TCPProxyConnections)
+	registry.MustRegister(TCPProxyActiveConnections)
+	registry.MustRegister(TCPProxyUpstreamErrors)
+	registry.MustRegister(TCPProxyBytes)
+	registry.MustRegister(TCPProxyConnectionBytes)
+	registry.MustRegister(TCPProxyConnectionDuration)
+	registry.MustRegister(TCPProxyDrainClosed)
 	registry.MustRegister(HealthRequests)
 	registry.MustRegister(HealthRequestDuration)
 	registry.MustRegister(HealthApp)
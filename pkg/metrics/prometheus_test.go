@@ -40,6 +40,10 @@ func TestMetrics_ActiveConnectionsInitialized(t *testing.T) {
 	assert.NotNil(t, ActiveConnections)
 }
 
+func TestMetrics_MonitoredPortsInitialized(t *testing.T) {
+	assert.NotNil(t, MonitoredPorts)
+}
+
 func TestMetrics_DrainPhaseActiveInitialized(t *testing.T) {
 	assert.NotNil(t, DrainPhaseActive)
 }
@@ -48,6 +52,10 @@ func TestMetrics_DrainDurationInitialized(t *testing.T) {
 	assert.NotNil(t, DrainDuration)
 }
 
+func TestMetrics_ConnectionsForceClosedInitialized(t *testing.T) {
+	assert.NotNil(t, ConnectionsForceClosed)
+}
+
 func TestMetrics_HealthRequestsInitialized(t *testing.T) {
 	assert.NotNil(t, HealthRequests)
 }
@@ -112,6 +120,142 @@ func TestMetrics_ActiveConnectionsGauge(t *testing.T) {
 	assert.Contains(t, string(body), "zerohalt_active_connections 42")
 }
 
+func TestMetrics_ActiveUnixConnectionsGauge(t *testing.T) {
+	ActiveUnixConnections.WithLabelValues("/run/app.sock").Set(5)
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), `zerohalt_active_unix_connections{path="/run/app.sock"} 5`)
+}
+
+func TestMetrics_ProxyMetrics(t *testing.T) {
+	ProxyRequests.Inc()
+	ProxyInFlightRequests.Set(2)
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), "zerohalt_proxy_requests_total")
+	assert.Contains(t, string(body), "zerohalt_proxy_in_flight_requests 2")
+
+	ProxyInFlightRequests.Set(0)
+}
+
+func TestMetrics_LoadSheddingMetrics(t *testing.T) {
+	ProxyShed.WithLabelValues("queue_full").Inc()
+	ProxyQueueDepth.Set(4)
+	ProxyConcurrencyLimit.Set(16)
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), `zerohalt_proxy_shed_total{reason="queue_full"}`)
+	assert.Contains(t, string(body), "zerohalt_proxy_queue_depth 4")
+	assert.Contains(t, string(body), "zerohalt_proxy_concurrency_limit 16")
+}
+
+func TestMetrics_TCPProxyMetrics(t *testing.T) {
+	TCPProxyConnections.Inc()
+	TCPProxyActiveConnections.Set(2)
+	TCPProxyBytes.WithLabelValues("to_app").Add(128)
+	TCPProxyConnectionBytes.WithLabelValues("to_client").Observe(512)
+	TCPProxyConnectionDuration.Observe(1.5)
+	TCPProxyDrainClosed.WithLabelValues("reset").Inc()
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), "zerohalt_tcp_proxy_connections_total")
+	assert.Contains(t, string(body), "zerohalt_tcp_proxy_active_connections 2")
+	assert.Contains(t, string(body), `zerohalt_tcp_proxy_bytes_total{direction="to_app"}`)
+	assert.Contains(t, string(body), `zerohalt_tcp_proxy_connection_bytes_bucket{direction="to_client"`)
+	assert.Contains(t, string(body), "zerohalt_tcp_proxy_connection_duration_seconds_count")
+	assert.Contains(t, string(body), `zerohalt_tcp_proxy_drain_closed_total{mode="reset"}`)
+
+	TCPProxyActiveConnections.Set(0)
+}
+
+func TestMetrics_RestartMetrics(t *testing.T) {
+	AppRestarts.WithLabelValues("success").Inc()
+	ProxyHoldRejected.WithLabelValues("timeout").Inc()
+	ProxyHeldRequests.Set(3)
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), `zerohalt_app_restarts_total{result="success"}`)
+	assert.Contains(t, string(body), `zerohalt_proxy_hold_rejected_total{reason="timeout"}`)
+	assert.Contains(t, string(body), "zerohalt_proxy_held_requests 3")
+
+	ProxyHeldRequests.Set(0)
+}
+
+func TestMetrics_ActiveUDPFlowsGauge(t *testing.T) {
+	ActiveUDPFlows.WithLabelValues("443").Set(7)
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), `zerohalt_active_udp_flows{port="443"} 7`)
+}
+
+func TestMetrics_ConnectionsByStateGauge(t *testing.T) {
+	ConnectionsByState.WithLabelValues("8080", "ESTABLISHED").Set(3)
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), `zerohalt_connections{port="8080",state="ESTABLISHED"} 3`)
+}
+
+func TestMetrics_ListenBacklogGauges(t *testing.T) {
+	ListenBacklog.WithLabelValues("8080").Set(2)
+	ListenBacklogLimit.WithLabelValues("8080").Set(128)
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), `zerohalt_listen_backlog{port="8080"} 2`)
+	assert.Contains(t, string(body), `zerohalt_listen_backlog_limit{port="8080"} 128`)
+}
+
+func TestMetrics_MonitoredPortsGauge(t *testing.T) {
+	MonitoredPorts.WithLabelValues("9090", "discovered").Set(1)
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), `zerohalt_monitored_ports{port="9090",source="discovered"} 1`)
+}
+
 func TestMetrics_DrainPhaseActiveGauge(t *testing.T) {
 	DrainPhaseActive.Set(1)
 
@@ -136,6 +280,18 @@ func TestMetrics_DrainDurationGauge(t *testing.T) {
 	assert.Contains(t, string(body), "zerohalt_drain_duration_seconds 30.5")
 }
 
+func TestMetrics_ConnectionsForceClosedCounter(t *testing.T) {
+	ConnectionsForceClosed.Inc()
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), "zerohalt_connections_force_closed_total")
+}
+
 func TestMetrics_HealthRequestsCounter(t *testing.T) {
 	handler := Handler()
 	req := httptest.NewRequest("GET", "/metrics", nil)
@@ -372,3 +528,19 @@ func TestResponseRecorder_Write(t *testing.T) {
 	assert.Equal(t, len(data), n)
 	assert.Equal(t, "test data", rec.body.String())
 }
+
+func TestMetrics_LifecycleMetrics(t *testing.T) {
+	NotifyMessages.WithLabelValues("ready").Inc()
+	NotifyWatchdogMissed.Inc()
+	HookRuns.WithLabelValues("pre-start-1", "success").Inc()
+
+	handler := Handler()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	assert.Contains(t, string(body), `zerohalt_notify_messages_total{type="ready"}`)
+	assert.Contains(t, string(body), "zerohalt_notify_watchdog_missed_total")
+	assert.Contains(t, string(body), `zerohalt_hook_runs_total{hook="pre-start-1",result="success"}`)
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"strconv"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+type portState struct {
+	port  uint16
+	state TCPState
+}
+
+type listenBacklog struct {
+	queued uint32
+	limit  uint32
+}
+
+// publishBreakdown exports socket counts on monitored ports by port and TCP
+// state, and the accept backlog of each listening port. LISTEN rows have no
+// remote address, so the remote filter only applies to the state counts.
+func (m *Monitor) publishBreakdown(conns []Connection, monitoredPorts map[uint16]bool, ownedInodes map[uint64]bool) {
+	byState := make(map[portState]int)
+	backlogs := make(map[uint16]listenBacklog)
+
+	for _, conn := range conns {
+		isMonitored := monitoredPorts[conn.LocalPort]
+		isOwned := ownedInodes == nil || ownedInodes[conn.Inode]
+
+		if !isMonitored || !isOwned {
+			continue
+		}
+
+		if conn.State == StateListen {
+			backlog := backlogs[conn.LocalPort]
+			backlog.queued += conn.RxQueue
+			backlog.limit += conn.TxQueue
+			backlogs[conn.LocalPort] = backlog
+			continue
+		}
+
+		if m.isCountedRemote(conn.RemoteAddr) {
+			byState[portState{port: conn.LocalPort, state: conn.State}]++
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	publishedStates := make(map[portState]bool, len(byState))
+	for key, count := range byState {
+		metrics.ConnectionsByState.WithLabelValues(strconv.Itoa(int(key.port)), key.state.String()).Set(float64(count))
+		publishedStates[key] = true
+	}
+
+	for key := range m.publishedStates {
+		if !publishedStates[key] {
+			metrics.ConnectionsByState.DeleteLabelValues(strconv.Itoa(int(key.port)), key.state.String())
+		}
+	}
+	m.publishedStates = publishedStates
+
+	publishedBacklogs := make(map[uint16]bool, len(backlogs))
+	for port, backlog := range backlogs {
+		portLabel := strconv.Itoa(int(port))
+		metrics.ListenBacklog.WithLabelValues(portLabel).Set(float64(backlog.queued))
+		metrics.ListenBacklogLimit.WithLabelValues(portLabel).Set(float64(backlog.limit))
+		publishedBacklogs[port] = true
+	}
+
+	for port := range m.publishedBacklogs {
+		if !publishedBacklogs[port] {
+			portLabel := strconv.Itoa(int(port))
+			metrics.ListenBacklog.DeleteLabelValues(portLabel)
+			metrics.ListenBacklogLimit.DeleteLabelValues(portLabel)
+		}
+	}
+	m.publishedBacklogs = publishedBacklogs
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func connectionsByState(t *testing.T, port string, state string) float64 {
+	var metric dto.Metric
+	err := metrics.ConnectionsByState.WithLabelValues(port, state).Write(&metric)
+	assert.NoError(t, err)
+	return metric.GetGauge().GetValue()
+}
+
+func listenBacklogValues(t *testing.T, port string) (float64, float64) {
+	var queued, limit dto.Metric
+	assert.NoError(t, metrics.ListenBacklog.WithLabelValues(port).Write(&queued))
+	assert.NoError(t, metrics.ListenBacklogLimit.WithLabelValues(port).Write(&limit))
+	return queued.GetGauge().GetValue(), limit.GetGauge().GetValue()
+}
+
+func TestMonitor_publishBreakdown(t *testing.T) {
+	m := NewMonitor([]uint16{8080, 9090}, 1*time.Second)
+
+	m.publishBreakdown([]Connection{
+		{LocalPort: 8080, State: StateListen, TxQueue: 128, RxQueue: 3},
+		{LocalPort: 8080, State: StateListen, TxQueue: 128, RxQueue: 1},
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "10.0.0.1"},
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "10.0.0.2"},
+		{LocalPort: 8080, State: StateTimeWait, RemoteAddr: "10.0.0.3"},
+		{LocalPort: 9090, State: StateCloseWait, RemoteAddr: "10.0.0.4"},
+		{LocalPort: 7070, State: StateEstablished, RemoteAddr: "10.0.0.5"},
+	}, m.monitoredPortSet(), nil)
+
+	assert.Equal(t, float64(2), connectionsByState(t, "8080", "ESTABLISHED"))
+	assert.Equal(t, float64(1), connectionsByState(t, "8080", "TIME_WAIT"))
+	assert.Equal(t, float64(1), connectionsByState(t, "9090", "CLOSE_WAIT"))
+
+	queued, limit := listenBacklogValues(t, "8080")
+	assert.Equal(t, float64(4), queued)
+	assert.Equal(t, float64(256), limit)
+}
+
+func TestMonitor_publishBreakdown_AppliesFilters(t *testing.T) {
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	filter, err := NewRemoteFilter([]string{"10.0.0.0/8"}, nil, false)
+	assert.NoError(t, err)
+	m.SetRemoteFilter(filter)
+
+	m.publishBreakdown([]Connection{
+		{LocalPort: 8080, State: StateListen, TxQueue: 64, RxQueue: 2, Inode: 1},
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "10.0.0.1", Inode: 2},
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "192.168.0.1", Inode: 3},
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "10.0.0.2", Inode: 4},
+	}, m.monitoredPortSet(), map[uint64]bool{1: true, 2: true, 3: true})
+
+	assert.Equal(t, float64(1), connectionsByState(t, "8080", "ESTABLISHED"))
+
+	queued, limit := listenBacklogValues(t, "8080")
+	assert.Equal(t, float64(2), queued)
+	assert.Equal(t, float64(64), limit)
+}
+
+func TestMonitor_publishBreakdown_DropsStaleLabels(t *testing.T) {
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+
+	m.publishBreakdown([]Connection{
+		{LocalPort: 8080, State: StateListen, TxQueue: 128},
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "10.0.0.1"},
+		{LocalPort: 8080, State: StateFinWait2, RemoteAddr: "10.0.0.2"},
+	}, m.monitoredPortSet(), nil)
+	m.publishBreakdown([]Connection{
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "10.0.0.1"},
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "10.0.0.3"},
+	}, m.monitoredPortSet(), nil)
+
+	assert.False(t, metrics.ConnectionsByState.DeleteLabelValues("8080", "FIN_WAIT2"), "a state that disappeared is deleted")
+	assert.False(t, metrics.ListenBacklog.DeleteLabelValues("8080"), "a listener that disappeared is deleted")
+	assert.False(t, metrics.ListenBacklogLimit.DeleteLabelValues("8080"))
+	assert.Equal(t, float64(2), connectionsByState(t, "8080", "ESTABLISHED"), "a state still present keeps its series")
+}
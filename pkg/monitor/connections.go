@@ -15,21 +15,76 @@
 package monitor
 
 import (
+	"context"
 	"errors"
 	"log/slog"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/jpasei/zerohalt/pkg/metrics"
+	"golang.org/x/sys/unix"
 )
 
 var (
-	ErrDrainTimeout = errors.New("connection drain timeout reached")
+	ErrDrainTimeout     = errors.New("connection drain timeout reached")
+	ErrDrainInterrupted = errors.New("connection drain interrupted")
 )
 
 type Monitor struct {
 	ports           []uint16
 	interval        time.Duration
 	steadyStateWait time.Duration
+	ignoredPorts    []uint16
+	remoteFilter    *RemoteFilter
+	processTreeOnly bool
+	appPID          int
+	unixPaths       []string
+	udpPorts        []uint16
+	udpSignal       UDPSignal
+	udpIdleTimeout  time.Duration
+
+	requestCounterPort uint16
+	requestCounter     RequestCounter
+
+	discoveryInterval time.Duration
+	discoveredPorts   []uint16
+
+	forceClose       bool
+	forceCloseAfter  time.Duration
+	maxConnectionAge time.Duration
+
+	mu        sync.Mutex
+	firstSeen map[connectionKey]time.Time
+	active    []Connection
+	// scanned is false until the first scan after Start. Connections found
+	// by that scan were opened while nothing was watching, so their age is
+	// unknown.
+	scanned bool
+
+	udpLastActive map[connectionKey]time.Time
+	stop          chan struct{}
+
+	// publishedStates and publishedBacklogs are the breakdown labels set on
+	// the last tick, so labels that disappear can be deleted.
+	publishedStates   map[portState]bool
+	publishedBacklogs map[uint16]bool
+}
+
+type connectionKey struct {
+	localAddr  string
+	localPort  uint16
+	remoteAddr string
+	remotePort uint16
+}
+
+func keyOf(conn Connection) connectionKey {
+	return connectionKey{
+		localAddr:  conn.LocalAddr,
+		localPort:  conn.LocalPort,
+		remoteAddr: conn.RemoteAddr,
+		remotePort: conn.RemotePort,
+	}
 }
 
 func NewMonitor(ports []uint16, interval time.Duration) *Monitor {
@@ -44,16 +99,86 @@ func (m *Monitor) SetSteadyStateWait(wait time.Duration) {
 	m.steadyStateWait = wait
 }
 
+// SetIgnoredPorts excludes ports that belong to zerohalt itself, such as the
+// health and metrics ports, even when they overlap the monitored ports.
+func (m *Monitor) SetIgnoredPorts(ports []uint16) {
+	m.ignoredPorts = ports
+}
+
+func (m *Monitor) SetRemoteFilter(filter *RemoteFilter) {
+	m.remoteFilter = filter
+}
+
+// SetProcessTreeOnly restricts counting to sockets held open by the
+// application process and its descendants, which matters when several
+// containers share a network namespace.
+func (m *Monitor) SetProcessTreeOnly(enabled bool) {
+	m.processTreeOnly = enabled
+}
+
+func (m *Monitor) SetAppProcess(appProcess *os.Process) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.appPID = appProcess.Pid
+}
+
+func (m *Monitor) getAppPID() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.appPID
+}
+
+// SetForceClose enables closing connections that outlive maxAge once the
+// drain has been running for at least after. A zero maxAge closes every
+// connection that is still open at that point.
+func (m *Monitor) SetForceClose(after time.Duration, maxAge time.Duration) {
+	m.forceClose = true
+	m.forceCloseAfter = after
+	m.maxConnectionAge = maxAge
+}
+
 func (m *Monitor) Start() {
-	go m.runMonitoringLoop()
+	stop := make(chan struct{})
+
+	m.mu.Lock()
+	m.stop = stop
+	m.scanned = false
+	m.mu.Unlock()
+
+	m.publishMonitoredPorts()
+
+	go m.runMonitoringLoop(stop)
+
+	if m.discoveryInterval > 0 {
+		go m.runDiscoveryLoop(stop)
+	}
+}
+
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop == nil {
+		return
+	}
+
+	close(m.stop)
+	m.stop = nil
 }
 
-func (m *Monitor) runMonitoringLoop() {
+func (m *Monitor) runMonitoringLoop(stop chan struct{}) {
 	ticker := time.NewTicker(m.interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		m.CountActiveConnections()
+	for {
+		select {
+		case <-ticker.C:
+			m.CountActiveConnections()
+		case <-stop:
+			return
+		}
 	}
 }
 
@@ -71,24 +196,165 @@ func (m *Monitor) CountActiveConnections() (int, error) {
 	}
 
 	allConns := append(tcpConns, tcp6Conns...)
+	ownedInodes := m.resolveOwnedInodes()
+	monitoredPorts := m.monitoredPortSet()
 
-	count := 0
+	var active []Connection
 	for _, conn := range allConns {
-		isMonitored := m.isMonitoredPort(conn.LocalPort)
+		isMonitored := monitoredPorts[conn.LocalPort] && !m.isRequestCountedPort(conn.LocalPort)
 		isActive := m.isActiveState(conn.State)
+		isCountedRemote := m.isCountedRemote(conn.RemoteAddr)
+		isOwned := ownedInodes == nil || ownedInodes[conn.Inode]
 
-		if isMonitored && isActive {
-			count++
+		if isMonitored && isActive && isCountedRemote && isOwned {
+			active = append(active, conn)
 		}
 	}
 
-	metrics.ActiveConnections.Set(float64(count))
-	slog.Debug("Active connections counted", "count", count, "monitored_ports", m.ports)
+	m.trackConnections(active)
+	m.publishBreakdown(allConns, monitoredPorts, ownedInodes)
+
+	unixCount, err := m.countUnixConnections(ownedInodes)
+	if err != nil {
+		return 0, err
+	}
+
+	udpCount, err := m.countUDPFlows(ownedInodes)
+	if err != nil {
+		return 0, err
+	}
+
+	requestCount := m.countInFlightRequests()
+
+	connectionCount := len(active) + unixCount
+	metrics.ActiveConnections.Set(float64(connectionCount))
+
+	count := connectionCount + udpCount + requestCount
+	slog.Debug("Active connections counted", "count", count, "tcp_count", len(active), "unix_count", unixCount, "udp_flow_count", udpCount, "in_flight_requests", requestCount, "monitored_ports", m.monitoredPorts(), "unix_sockets", m.unixPaths, "udp_ports", m.udpPorts)
 
 	return count, nil
 }
 
-func (m *Monitor) WaitForZeroConnections(timeout time.Duration) error {
+// resolveOwnedInodes returns nil when every socket should be counted, either
+// because filtering is disabled or because ownership cannot be determined.
+func (m *Monitor) resolveOwnedInodes() map[uint64]bool {
+	if !m.processTreeOnly {
+		return nil
+	}
+
+	pid := m.getAppPID()
+	if pid == 0 {
+		return nil
+	}
+
+	inodes, err := ownedSocketInodes(pid)
+	if err != nil {
+		slog.Warn("Failed to resolve application sockets, counting all connections", "pid", pid, "error", err)
+		return nil
+	}
+
+	return inodes
+}
+
+func (m *Monitor) trackConnections(active []Connection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	seen := make(map[connectionKey]time.Time, len(active))
+
+	// Connections already open on the first scan are treated as having
+	// reached the max age, so a forced close does not give them another one.
+	newSince := now
+	if !m.scanned {
+		newSince = now.Add(-m.maxConnectionAge)
+	}
+
+	for _, conn := range active {
+		key := keyOf(conn)
+
+		firstSeen, known := m.firstSeen[key]
+		if !known {
+			firstSeen = newSince
+		}
+
+		seen[key] = firstSeen
+	}
+
+	m.firstSeen = seen
+	m.active = active
+	m.scanned = true
+}
+
+// connectionAge reports how long a connection has been observed by the
+// monitor, which is bounded below by the monitoring interval.
+func (m *Monitor) connectionAge(conn Connection, now time.Time) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	firstSeen, known := m.firstSeen[keyOf(conn)]
+	if !known {
+		return 0
+	}
+
+	return now.Sub(firstSeen)
+}
+
+func (m *Monitor) expiredConnections() []Connection {
+	m.mu.Lock()
+	active := m.active
+	m.mu.Unlock()
+
+	now := time.Now()
+	var expired []Connection
+
+	for _, conn := range active {
+		age := m.connectionAge(conn, now)
+
+		if age >= m.maxConnectionAge {
+			expired = append(expired, conn)
+		}
+	}
+
+	return expired
+}
+
+func (m *Monitor) enforceMaxConnectionAge(drainStart time.Time) {
+	if !m.forceClose {
+		return
+	}
+
+	forceCloseStarted := time.Since(drainStart) >= m.forceCloseAfter
+	if !forceCloseStarted {
+		return
+	}
+
+	now := time.Now()
+	for _, conn := range m.expiredConnections() {
+		m.forceCloseConnection(conn, m.connectionAge(conn, now))
+	}
+}
+
+func (m *Monitor) forceCloseConnection(conn Connection, age time.Duration) {
+	err := destroySocket(conn)
+
+	if errors.Is(err, unix.ENOENT) {
+		slog.Debug("Connection already closed before force close", "local_addr", conn.LocalAddr, "local_port", conn.LocalPort, "remote_addr", conn.RemoteAddr, "remote_port", conn.RemotePort)
+		return
+	}
+
+	if err != nil {
+		slog.Warn("Failed to force close connection", "local_addr", conn.LocalAddr, "local_port", conn.LocalPort, "remote_addr", conn.RemoteAddr, "remote_port", conn.RemotePort, "error", err)
+		return
+	}
+
+	metrics.ConnectionsForceClosed.Inc()
+	slog.Info("Force closed connection", "local_addr", conn.LocalAddr, "local_port", conn.LocalPort, "remote_addr", conn.RemoteAddr, "remote_port", conn.RemotePort, "age", age)
+}
+
+// WaitForZeroConnections blocks until no monitored connections remain, the
+// timeout expires, or ctx is cancelled.
+func (m *Monitor) WaitForZeroConnections(ctx context.Context, timeout time.Duration) error {
 	start := time.Now()
 	metrics.DrainPhaseActive.Set(1)
 	defer func() {
@@ -96,9 +362,22 @@ func (m *Monitor) WaitForZeroConnections(timeout time.Duration) error {
 		metrics.DrainDuration.Set(time.Since(start).Seconds())
 	}()
 
+	if m.discoveryInterval > 0 {
+		m.DiscoverPorts()
+	}
+
 	deadline := time.Now().Add(timeout)
 	slog.Info("Waiting for connections to drain", "timeout", timeout, "check_interval", m.interval, "steady_state_wait", m.steadyStateWait)
 
+	return m.waitUntilDrained(ctx, start, deadline)
+}
+
+func (m *Monitor) waitUntilDrained(ctx context.Context, start time.Time, deadline time.Time) error {
+	if ctx.Err() != nil {
+		slog.Warn("Connection drain interrupted")
+		return ErrDrainInterrupted
+	}
+
 	count, err := m.CountActiveConnections()
 	if err != nil {
 		slog.Error("Error counting active connections", "error", err)
@@ -111,7 +390,7 @@ func (m *Monitor) WaitForZeroConnections(timeout time.Duration) error {
 		shouldWaitForSteadyState := steadyStateEnabled
 
 		if shouldWaitForSteadyState {
-			return m.waitForSteadyState(deadline)
+			return m.waitForSteadyState(ctx, start, deadline)
 		}
 
 		slog.Info("All connections drained successfully")
@@ -123,6 +402,9 @@ func (m *Monitor) WaitForZeroConnections(timeout time.Duration) error {
 
 	for {
 		select {
+		case <-ctx.Done():
+			slog.Warn("Connection drain interrupted")
+			return ErrDrainInterrupted
 		case <-ticker.C:
 			count, err := m.CountActiveConnections()
 			if err != nil {
@@ -134,7 +416,7 @@ func (m *Monitor) WaitForZeroConnections(timeout time.Duration) error {
 				shouldWaitForSteadyState := steadyStateEnabled
 
 				if shouldWaitForSteadyState {
-					return m.waitForSteadyState(deadline)
+					return m.waitForSteadyState(ctx, start, deadline)
 				}
 
 				slog.Info("All connections drained successfully")
@@ -144,14 +426,17 @@ func (m *Monitor) WaitForZeroConnections(timeout time.Duration) error {
 			slog.Debug("Connections still active, continuing to wait", "active_count", count)
 
 			if time.Now().After(deadline) {
-				slog.Warn("Connection drain timeout exceeded", "active_count", count, "timeout", timeout)
+				slog.Warn("Connection drain timeout exceeded", "active_count", count, "timeout", deadline.Sub(start))
 				return ErrDrainTimeout
 			}
+
+			m.enforceMaxConnectionAge(start)
 		}
 	}
 }
 
-func (m *Monitor) waitForSteadyState(deadline time.Time) error {
+func (m *Monitor) waitForSteadyState(ctx context.Context, start time.Time, deadline time.Time) error {
+
 	steadyStateCheckInterval := 50 * time.Millisecond
 	slog.Info("Connections reached zero, starting steady state wait", "wait_duration", m.steadyStateWait, "check_interval", steadyStateCheckInterval)
 	steadyStateDeadline := time.Now().Add(m.steadyStateWait)
@@ -161,6 +446,9 @@ func (m *Monitor) waitForSteadyState(deadline time.Time) error {
 
 	for {
 		select {
+		case <-ctx.Done():
+			slog.Warn("Connection drain interrupted during steady state wait")
+			return ErrDrainInterrupted
 		case <-ticker.C:
 			isAfterOverallDeadline := time.Now().After(deadline)
 
@@ -177,7 +465,7 @@ func (m *Monitor) waitForSteadyState(deadline time.Time) error {
 
 			if count > 0 {
 				slog.Info("Connections increased during steady state wait, resetting timer", "active_count", count)
-				return m.WaitForZeroConnections(time.Until(deadline))
+				return m.waitUntilDrained(ctx, start, deadline)
 			}
 
 			isAfterSteadyStateDeadline := time.Now().After(steadyStateDeadline)
@@ -192,13 +480,26 @@ func (m *Monitor) waitForSteadyState(deadline time.Time) error {
 	}
 }
 
-func (m *Monitor) isMonitoredPort(port uint16) bool {
-	for _, p := range m.ports {
-		if p == port {
-			return true
-		}
+// monitoredPortSet returns the monitored ports without the ignored ones, so
+// each /proc row is a map lookup.
+func (m *Monitor) monitoredPortSet() map[uint16]bool {
+	ports := make(map[uint16]bool)
+	for _, port := range m.monitoredPorts() {
+		ports[port] = true
 	}
-	return false
+
+	for _, port := range m.ignoredPorts {
+		delete(ports, port)
+	}
+	return ports
+}
+
+func (m *Monitor) isCountedRemote(remoteAddr string) bool {
+	if m.remoteFilter == nil {
+		return true
+	}
+
+	return m.remoteFilter.Allows(remoteAddr)
 }
 
 func (m *Monitor) isActiveState(state TCPState) bool {
@@ -15,11 +15,14 @@
 package monitor
 
 import (
+	"context"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
 )
 
 func TestNewMonitor(t *testing.T) {
@@ -33,8 +36,8 @@ func TestNewMonitor(t *testing.T) {
 	assert.Equal(t, interval, m.interval)
 }
 
-func TestMonitor_isMonitoredPort(t *testing.T) {
-	m := NewMonitor([]uint16{8080, 9090}, 1*time.Second)
+func TestMonitor_monitoredPortSet(t *testing.T) {
+	m := NewMonitor([]uint16{8080, 9090, 9091}, 1*time.Second)
 
 	tests := []struct {
 		name string
@@ -45,11 +48,13 @@ func TestMonitor_isMonitoredPort(t *testing.T) {
 		{"monitored port 9090", 9090, true},
 		{"unmonitored port 80", 80, false},
 		{"unmonitored port 3000", 3000, false},
+		{"ignored port 9091", 9091, false},
 	}
 
+	m.SetIgnoredPorts([]uint16{9091})
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := m.isMonitoredPort(tt.port)
+			got := m.monitoredPortSet()[tt.port]
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -78,7 +83,7 @@ func TestMonitor_WaitForZeroConnections_Immediate(t *testing.T) {
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(100 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 100*time.Millisecond)
 
 	assert.NoError(t, err)
 }
@@ -138,7 +143,7 @@ func TestMonitor_WaitForZeroConnections_ErrorInCount(t *testing.T) {
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(50 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 50*time.Millisecond)
 	assert.Error(t, err)
 }
 
@@ -161,7 +166,7 @@ func TestMonitor_WaitForZeroConnections_Timeout(t *testing.T) {
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(30 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 30*time.Millisecond)
 	assert.Equal(t, ErrDrainTimeout, err)
 }
 
@@ -208,10 +213,18 @@ func TestMonitor_Start(t *testing.T) {
 	m.Start()
 
 	time.Sleep(120 * time.Millisecond)
+	m.Stop()
 
 	assert.GreaterOrEqual(t, callCount, 2)
 }
 
+func TestMonitor_Stop_WithoutStart(t *testing.T) {
+	m := NewMonitor([]uint16{8080}, 50*time.Millisecond)
+
+	m.Stop()
+	m.Stop()
+}
+
 func TestMonitor_WaitForZeroConnections_EventualSuccess(t *testing.T) {
 	m := &Monitor{
 		ports:    []uint16{8080},
@@ -244,7 +257,7 @@ func TestMonitor_WaitForZeroConnections_EventualSuccess(t *testing.T) {
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(200 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 200*time.Millisecond)
 
 	assert.NoError(t, err)
 	assert.GreaterOrEqual(t, callIndex, 4)
@@ -328,7 +341,7 @@ func TestMonitor_WaitForZeroConnections_ErrorInTickerLoop(t *testing.T) {
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(200 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 200*time.Millisecond)
 
 	assert.Error(t, err)
 	assert.Equal(t, os.ErrPermission, err)
@@ -366,7 +379,7 @@ func TestMonitor_WaitForZeroConnections_SteadyStateDisabled(t *testing.T) {
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(200 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 200*time.Millisecond)
 
 	assert.NoError(t, err)
 	assert.GreaterOrEqual(t, callIndex, 4)
@@ -395,7 +408,7 @@ func TestMonitor_WaitForZeroConnections_SteadyStateWaitSuccess(t *testing.T) {
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(500 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 500*time.Millisecond)
 
 	assert.NoError(t, err)
 	assert.GreaterOrEqual(t, callIndex, 3)
@@ -425,7 +438,7 @@ func TestMonitor_WaitForZeroConnections_SteadyStateResetOnConnectionIncrease(t *
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(500 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 500*time.Millisecond)
 
 	assert.NoError(t, err)
 	assert.GreaterOrEqual(t, callIndex, 6)
@@ -454,7 +467,7 @@ func TestMonitor_WaitForZeroConnections_SteadyStateTimeoutDuringWait(t *testing.
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(80 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 80*time.Millisecond)
 
 	assert.Equal(t, ErrDrainTimeout, err)
 }
@@ -474,7 +487,7 @@ func TestMonitor_WaitForZeroConnections_SteadyStateImmediateZero(t *testing.T) {
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(500 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 500*time.Millisecond)
 
 	assert.NoError(t, err)
 }
@@ -508,8 +521,311 @@ func TestMonitor_WaitForZeroConnections_SteadyStateErrorDuringWait(t *testing.T)
 		parseProcNetTCP = origParseProcNetTCP
 	}()
 
-	err := m.WaitForZeroConnections(500 * time.Millisecond)
+	err := m.WaitForZeroConnections(context.Background(), 500*time.Millisecond)
 
 	assert.Error(t, err)
 	assert.Equal(t, os.ErrPermission, err)
 }
+
+func TestMonitor_SetForceClose(t *testing.T) {
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+
+	m.SetForceClose(10*time.Second, 5*time.Minute)
+
+	assert.True(t, m.forceClose)
+	assert.Equal(t, 10*time.Second, m.forceCloseAfter)
+	assert.Equal(t, 5*time.Minute, m.maxConnectionAge)
+}
+
+func TestMonitor_CountActiveConnections_TracksFirstSeen(t *testing.T) {
+	m := &Monitor{
+		ports:    []uint16{8080},
+		interval: 1 * time.Second,
+	}
+
+	conns := []Connection{
+		{LocalAddr: "10.0.0.1", LocalPort: 8080, RemoteAddr: "10.0.0.2", RemotePort: 40000, State: StateEstablished},
+	}
+
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		return conns, nil
+	}
+	defer func() {
+		parseProcNetTCP = origParseProcNetTCP
+	}()
+
+	m.CountActiveConnections()
+	firstSeen := m.firstSeen[keyOf(conns[0])]
+
+	time.Sleep(10 * time.Millisecond)
+	m.CountActiveConnections()
+
+	assert.Equal(t, firstSeen, m.firstSeen[keyOf(conns[0])])
+	assert.GreaterOrEqual(t, m.connectionAge(conns[0], time.Now()), 10*time.Millisecond)
+
+	conns = []Connection{}
+	m.CountActiveConnections()
+
+	assert.Empty(t, m.firstSeen)
+}
+
+func TestMonitor_trackConnections_FirstScanStartsAtMaxAge(t *testing.T) {
+	existing := Connection{LocalAddr: "10.0.0.1", LocalPort: 8080, RemoteAddr: "10.0.0.2", RemotePort: 40000, State: StateEstablished}
+	opened := Connection{LocalAddr: "10.0.0.1", LocalPort: 8080, RemoteAddr: "10.0.0.3", RemotePort: 40001, State: StateEstablished}
+
+	m := &Monitor{ports: []uint16{8080}}
+	m.SetForceClose(0, time.Minute)
+
+	m.trackConnections([]Connection{existing})
+	m.trackConnections([]Connection{existing, opened})
+
+	assert.Equal(t, []Connection{existing}, m.expiredConnections(), "a connection older than the monitor is already at the max age")
+}
+
+func TestMonitor_expiredConnections(t *testing.T) {
+	old := Connection{LocalAddr: "10.0.0.1", LocalPort: 8080, RemoteAddr: "10.0.0.2", RemotePort: 40000, State: StateEstablished}
+	young := Connection{LocalAddr: "10.0.0.1", LocalPort: 8080, RemoteAddr: "10.0.0.3", RemotePort: 40001, State: StateEstablished}
+
+	now := time.Now()
+	m := &Monitor{
+		ports:            []uint16{8080},
+		maxConnectionAge: 1 * time.Minute,
+		active:           []Connection{old, young},
+		firstSeen: map[connectionKey]time.Time{
+			keyOf(old):   now.Add(-2 * time.Minute),
+			keyOf(young): now,
+		},
+	}
+
+	assert.Equal(t, []Connection{old}, m.expiredConnections())
+
+	m.maxConnectionAge = 0
+	assert.Len(t, m.expiredConnections(), 2)
+}
+
+func TestMonitor_WaitForZeroConnections_ForceClosesAgedConnections(t *testing.T) {
+	m := &Monitor{
+		ports:    []uint16{8080},
+		interval: 10 * time.Millisecond,
+	}
+	m.SetForceClose(20*time.Millisecond, 0)
+
+	conn := Connection{LocalAddr: "10.0.0.1", LocalPort: 8080, RemoteAddr: "10.0.0.2", RemotePort: 40000, State: StateEstablished}
+
+	var mu sync.Mutex
+	closed := false
+
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if closed {
+			return []Connection{}, nil
+		}
+		return []Connection{conn}, nil
+	}
+	origDestroySocket := destroySocket
+	var destroyed []Connection
+	destroySocket = func(c Connection) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		destroyed = append(destroyed, c)
+		closed = true
+		return nil
+	}
+	defer func() {
+		parseProcNetTCP = origParseProcNetTCP
+		destroySocket = origDestroySocket
+	}()
+
+	err := m.WaitForZeroConnections(context.Background(), 1*time.Second)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, destroyed)
+	assert.Equal(t, conn, destroyed[0])
+}
+
+func TestMonitor_WaitForZeroConnections_NoForceCloseByDefault(t *testing.T) {
+	m := &Monitor{
+		ports:    []uint16{8080},
+		interval: 10 * time.Millisecond,
+	}
+
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		return []Connection{{LocalPort: 8080, State: StateEstablished}}, nil
+	}
+	origDestroySocket := destroySocket
+	destroyCalled := false
+	destroySocket = func(c Connection) error {
+		destroyCalled = true
+		return nil
+	}
+	defer func() {
+		parseProcNetTCP = origParseProcNetTCP
+		destroySocket = origDestroySocket
+	}()
+
+	err := m.WaitForZeroConnections(context.Background(), 50*time.Millisecond)
+
+	assert.Equal(t, ErrDrainTimeout, err)
+	assert.False(t, destroyCalled)
+}
+
+func TestMonitor_forceCloseConnection_Errors(t *testing.T) {
+	m := &Monitor{ports: []uint16{8080}}
+	conn := Connection{LocalAddr: "10.0.0.1", LocalPort: 8080, RemoteAddr: "10.0.0.2", RemotePort: 40000}
+
+	origDestroySocket := destroySocket
+	defer func() {
+		destroySocket = origDestroySocket
+	}()
+
+	for _, destroyErr := range []error{unix.ENOENT, unix.EPERM} {
+		destroySocket = func(c Connection) error {
+			return destroyErr
+		}
+
+		m.forceCloseConnection(conn, time.Minute)
+	}
+}
+
+func TestMonitor_CountActiveConnections_IgnoredPorts(t *testing.T) {
+	m := NewMonitor([]uint16{8080, 8888}, 1*time.Second)
+	m.SetIgnoredPorts([]uint16{8888})
+
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		return []Connection{
+			{LocalPort: 8080, State: StateEstablished},
+			{LocalPort: 8888, State: StateEstablished},
+		}, nil
+	}
+	defer func() {
+		parseProcNetTCP = origParseProcNetTCP
+	}()
+
+	count, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestMonitor_CountActiveConnections_RemoteFilter(t *testing.T) {
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+
+	filter, err := NewRemoteFilter(nil, []string{"10.0.0.0/24"}, true)
+	assert.NoError(t, err)
+	m.SetRemoteFilter(filter)
+
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		return []Connection{
+			{LocalPort: 8080, RemoteAddr: "192.168.1.10", State: StateEstablished},
+			{LocalPort: 8080, RemoteAddr: "10.0.0.7", State: StateEstablished},
+			{LocalPort: 8080, RemoteAddr: "127.0.0.1", State: StateEstablished},
+		}, nil
+	}
+	defer func() {
+		parseProcNetTCP = origParseProcNetTCP
+	}()
+
+	count, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestMonitor_CountActiveConnections_ProcessTreeOnly(t *testing.T) {
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetProcessTreeOnly(true)
+	m.SetAppProcess(&os.Process{Pid: 100})
+
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		if path != "/proc/net/tcp" {
+			return []Connection{}, nil
+		}
+		return []Connection{
+			{LocalPort: 8080, State: StateEstablished, Inode: 1001},
+			{LocalPort: 8080, State: StateEstablished, Inode: 2001},
+		}, nil
+	}
+	origOwnedSocketInodes := ownedSocketInodes
+	ownedSocketInodes = func(rootPID int) (map[uint64]bool, error) {
+		assert.Equal(t, 100, rootPID)
+		return map[uint64]bool{1001: true}, nil
+	}
+	defer func() {
+		parseProcNetTCP = origParseProcNetTCP
+		ownedSocketInodes = origOwnedSocketInodes
+	}()
+
+	count, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestMonitor_CountActiveConnections_ProcessTreeOnlyFallsBack(t *testing.T) {
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetProcessTreeOnly(true)
+
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		if path != "/proc/net/tcp" {
+			return []Connection{}, nil
+		}
+		return []Connection{
+			{LocalPort: 8080, State: StateEstablished, Inode: 1001},
+			{LocalPort: 8080, State: StateEstablished, Inode: 2001},
+		}, nil
+	}
+	origOwnedSocketInodes := ownedSocketInodes
+	ownedSocketInodes = func(rootPID int) (map[uint64]bool, error) {
+		return nil, os.ErrPermission
+	}
+	defer func() {
+		parseProcNetTCP = origParseProcNetTCP
+		ownedSocketInodes = origOwnedSocketInodes
+	}()
+
+	count, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count, "no app process yet, every connection is counted")
+
+	m.SetAppProcess(&os.Process{Pid: 100})
+
+	count, err = m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count, "ownership lookup failed, every connection is counted")
+}
+
+func TestMonitor_WaitForZeroConnections_Cancelled(t *testing.T) {
+	m := &Monitor{
+		ports:    []uint16{8080},
+		interval: 10 * time.Millisecond,
+	}
+
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		return []Connection{
+			{
+				LocalPort: 8080,
+				State:     StateEstablished,
+			},
+		}, nil
+	}
+	defer func() {
+		parseProcNetTCP = origParseProcNetTCP
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := m.WaitForZeroConnections(ctx, 5*time.Second)
+
+	assert.ErrorIs(t, err, ErrDrainInterrupted)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+// SetPortDiscovery enables periodic discovery of ports the application
+// process tree is listening on. Discovered ports are monitored in addition to
+// the configured ones and are kept even after the listener closes, since
+// established connections outlive it during a drain.
+func (m *Monitor) SetPortDiscovery(interval time.Duration) {
+	m.discoveryInterval = interval
+}
+
+func (m *Monitor) runDiscoveryLoop(stop chan struct{}) {
+	ticker := time.NewTicker(m.discoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.DiscoverPorts()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// DiscoverPorts scans for LISTEN sockets owned by the application process
+// tree and returns the ports that were not monitored before.
+func (m *Monitor) DiscoverPorts() []uint16 {
+	pid := m.getAppPID()
+	if pid == 0 {
+		slog.Debug("Port discovery skipped, application not started")
+		return nil
+	}
+
+	inodes, err := ownedSocketInodes(pid)
+	if err != nil {
+		slog.Warn("Port discovery failed to resolve application sockets", "pid", pid, "error", err)
+		return nil
+	}
+
+	listening, err := m.listeningPorts(inodes)
+	if err != nil {
+		slog.Warn("Port discovery failed to read listening sockets", "error", err)
+		return nil
+	}
+
+	var added []uint16
+	for _, port := range listening {
+		if m.addDiscoveredPort(port) {
+			added = append(added, port)
+		}
+	}
+
+	if len(added) > 0 {
+		slog.Info("Discovered application listening ports", "ports", added, "monitored_ports", m.monitoredPorts())
+		m.publishMonitoredPorts()
+	}
+
+	return added
+}
+
+func (m *Monitor) listeningPorts(ownedInodes map[uint64]bool) ([]uint16, error) {
+	var ports []uint16
+
+	for _, path := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		conns, err := parseProcNetTCP(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, conn := range conns {
+			isListening := conn.State == StateListen
+			isOwned := ownedInodes[conn.Inode]
+			isIgnored := slices.Contains(m.ignoredPorts, conn.LocalPort)
+
+			if isListening && isOwned && !isIgnored && !slices.Contains(ports, conn.LocalPort) {
+				ports = append(ports, conn.LocalPort)
+			}
+		}
+	}
+
+	return ports, nil
+}
+
+func (m *Monitor) addDiscoveredPort(port uint16) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alreadyMonitored := slices.Contains(m.ports, port) || slices.Contains(m.discoveredPorts, port)
+	if alreadyMonitored {
+		return false
+	}
+
+	m.discoveredPorts = append(m.discoveredPorts, port)
+	return true
+}
+
+func (m *Monitor) monitoredPorts() []uint16 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ports := make([]uint16, 0, len(m.ports)+len(m.discoveredPorts))
+	ports = append(ports, m.ports...)
+	return append(ports, m.discoveredPorts...)
+}
+
+func (m *Monitor) publishMonitoredPorts() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, port := range m.ports {
+		metrics.MonitoredPorts.WithLabelValues(strconv.Itoa(int(port)), "configured").Set(1)
+	}
+
+	for _, port := range m.discoveredPorts {
+		metrics.MonitoredPorts.WithLabelValues(strconv.Itoa(int(port)), "discovered").Set(1)
+	}
+}
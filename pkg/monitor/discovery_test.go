@@ -0,0 +1,143 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockDiscoverySources(t *testing.T, conns []Connection, owned map[uint64]bool) {
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		if path != "/proc/net/tcp" {
+			return []Connection{}, nil
+		}
+		return conns, nil
+	}
+
+	origOwnedSocketInodes := ownedSocketInodes
+	ownedSocketInodes = func(rootPID int) (map[uint64]bool, error) {
+		return owned, nil
+	}
+
+	t.Cleanup(func() {
+		parseProcNetTCP = origParseProcNetTCP
+		ownedSocketInodes = origOwnedSocketInodes
+	})
+}
+
+func TestMonitor_SetPortDiscovery(t *testing.T) {
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+
+	m.SetPortDiscovery(5 * time.Second)
+
+	assert.Equal(t, 5*time.Second, m.discoveryInterval)
+}
+
+func TestMonitor_DiscoverPorts(t *testing.T) {
+	mockDiscoverySources(t, []Connection{
+		{LocalPort: 8080, State: StateListen, Inode: 1},
+		{LocalPort: 9090, State: StateListen, Inode: 2},
+		{LocalPort: 9090, State: StateListen, Inode: 3},
+		{LocalPort: 7070, State: StateListen, Inode: 100},
+		{LocalPort: 8888, State: StateListen, Inode: 4},
+		{LocalPort: 6060, State: StateEstablished, Inode: 5},
+	}, map[uint64]bool{1: true, 2: true, 3: true, 4: true, 5: true})
+
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetIgnoredPorts([]uint16{8888})
+	m.SetAppProcess(&os.Process{Pid: 100})
+
+	added := m.DiscoverPorts()
+
+	assert.Equal(t, []uint16{9090}, added)
+	assert.Equal(t, []uint16{8080, 9090}, m.monitoredPorts())
+	assert.True(t, m.monitoredPortSet()[9090])
+
+	assert.Empty(t, m.DiscoverPorts())
+}
+
+func TestMonitor_DiscoverPorts_NoAppProcess(t *testing.T) {
+	mockDiscoverySources(t, []Connection{
+		{LocalPort: 9090, State: StateListen, Inode: 2},
+	}, map[uint64]bool{2: true})
+
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+
+	assert.Empty(t, m.DiscoverPorts())
+}
+
+func TestMonitor_DiscoverPorts_Errors(t *testing.T) {
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetAppProcess(&os.Process{Pid: 100})
+
+	origOwnedSocketInodes := ownedSocketInodes
+	origParseProcNetTCP := parseProcNetTCP
+	defer func() {
+		ownedSocketInodes = origOwnedSocketInodes
+		parseProcNetTCP = origParseProcNetTCP
+	}()
+
+	ownedSocketInodes = func(rootPID int) (map[uint64]bool, error) {
+		return nil, os.ErrPermission
+	}
+	assert.Empty(t, m.DiscoverPorts())
+
+	ownedSocketInodes = func(rootPID int) (map[uint64]bool, error) {
+		return map[uint64]bool{}, nil
+	}
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		return nil, os.ErrNotExist
+	}
+	assert.Empty(t, m.DiscoverPorts())
+}
+
+func TestMonitor_DiscoveredPortsCountedDuringDrain(t *testing.T) {
+	mockDiscoverySources(t, []Connection{
+		{LocalPort: 9090, State: StateListen, Inode: 2},
+		{LocalPort: 9090, State: StateEstablished, Inode: 3},
+	}, map[uint64]bool{2: true, 3: true})
+
+	m := NewMonitor([]uint16{8080}, 10*time.Millisecond)
+	m.SetPortDiscovery(1 * time.Hour)
+	m.SetAppProcess(&os.Process{Pid: 100})
+
+	err := m.WaitForZeroConnections(context.Background(), 30*time.Millisecond)
+
+	assert.Equal(t, ErrDrainTimeout, err)
+	assert.Contains(t, m.monitoredPorts(), uint16(9090))
+}
+
+func TestMonitor_Start_RunsDiscoveryLoop(t *testing.T) {
+	mockDiscoverySources(t, []Connection{
+		{LocalPort: 9090, State: StateListen, Inode: 2},
+	}, map[uint64]bool{2: true})
+
+	m := NewMonitor([]uint16{8080}, 1*time.Hour)
+	m.SetPortDiscovery(10 * time.Millisecond)
+	m.SetAppProcess(&os.Process{Pid: 100})
+
+	m.Start()
+	defer m.Stop()
+
+	assert.Eventually(t, func() bool {
+		return len(m.monitoredPorts()) == 2
+	}, 1*time.Second, 10*time.Millisecond)
+}
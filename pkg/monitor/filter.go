@@ -0,0 +1,92 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"fmt"
+	"net"
+)
+
+type RemoteFilter struct {
+	include         []*net.IPNet
+	exclude         []*net.IPNet
+	excludeLoopback bool
+}
+
+func NewRemoteFilter(include []string, exclude []string, excludeLoopback bool) (*RemoteFilter, error) {
+	includeNets, err := parseCIDRs(include)
+	if err != nil {
+		return nil, err
+	}
+
+	excludeNets, err := parseCIDRs(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RemoteFilter{
+		include:         includeNets,
+		exclude:         excludeNets,
+		excludeLoopback: excludeLoopback,
+	}, nil
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
+
+// Allows reports whether a connection from remoteAddr should be counted.
+// Addresses that cannot be parsed are always counted so that a filter can
+// never make a drain finish early by accident.
+func (f *RemoteFilter) Allows(remoteAddr string) bool {
+	ip := net.ParseIP(remoteAddr)
+	if ip == nil {
+		return true
+	}
+
+	if f.excludeLoopback && ip.IsLoopback() {
+		return false
+	}
+
+	if containsIP(f.exclude, ip) {
+		return false
+	}
+
+	hasIncludeList := len(f.include) > 0
+	if hasIncludeList {
+		return containsIP(f.include, ip)
+	}
+
+	return true
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRemoteFilter_InvalidCIDR(t *testing.T) {
+	_, err := NewRemoteFilter([]string{"10.0.0.0/8", "not-a-cidr"}, nil, false)
+	assert.Error(t, err)
+
+	_, err = NewRemoteFilter(nil, []string{"10.0.0.1"}, false)
+	assert.Error(t, err)
+}
+
+func TestRemoteFilter_Allows(t *testing.T) {
+	tests := []struct {
+		name            string
+		include         []string
+		exclude         []string
+		excludeLoopback bool
+		remoteAddr      string
+		want            bool
+	}{
+		{"no rules", nil, nil, false, "10.1.2.3", true},
+		{"loopback counted by default", nil, nil, false, "127.0.0.1", true},
+		{"loopback excluded", nil, nil, true, "127.0.0.1", false},
+		{"ipv6 loopback excluded", nil, nil, true, "::1", false},
+		{"excluded cidr", nil, []string{"10.0.0.0/8"}, false, "10.1.2.3", false},
+		{"outside excluded cidr", nil, []string{"10.0.0.0/8"}, false, "192.168.1.1", true},
+		{"inside included cidr", []string{"192.168.0.0/16"}, nil, false, "192.168.1.1", true},
+		{"outside included cidr", []string{"192.168.0.0/16"}, nil, false, "10.1.2.3", false},
+		{"exclude wins over include", []string{"10.0.0.0/8"}, []string{"10.0.0.0/24"}, false, "10.0.0.5", false},
+		{"ipv6 cidr", []string{"fd00::/8"}, nil, false, "fd00::1", true},
+		{"unparseable address counted", []string{"10.0.0.0/8"}, nil, true, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewRemoteFilter(tt.include, tt.exclude, tt.excludeLoopback)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.want, filter.Allows(tt.remoteAddr))
+		})
+	}
+}
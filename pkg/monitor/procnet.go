@@ -19,6 +19,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -30,7 +31,11 @@ type Connection struct {
 	RemoteAddr string
 	RemotePort uint16
 	State      TCPState
+	TxQueue    uint32
+	RxQueue    uint32
 	UID        uint32
+	Inode      uint64
+	IPv6       bool
 }
 
 var parseProcNetTCP = parseProcNetTCPImpl
@@ -63,7 +68,9 @@ func parseProcNetTCPImpl(path string) ([]Connection, error) {
 		remoteAddr, remotePort := parseAddress(fields[2])
 
 		state, _ := strconv.ParseUint(fields[3], 16, 8)
+		txQueue, rxQueue := parseQueues(fields[4])
 		uid, _ := strconv.ParseUint(fields[7], 10, 32)
+		inode, _ := strconv.ParseUint(fields[9], 10, 64)
 
 		conn := Connection{
 			LocalAddr:  localAddr,
@@ -71,7 +78,11 @@ func parseProcNetTCPImpl(path string) ([]Connection, error) {
 			RemoteAddr: remoteAddr,
 			RemotePort: remotePort,
 			State:      TCPState(state),
+			TxQueue:    txQueue,
+			RxQueue:    rxQueue,
 			UID:        uint32(uid),
+			Inode:      inode,
+			IPv6:       isIPv6Address(fields[1]),
 		}
 
 		conns = append(conns, conn)
@@ -96,10 +107,33 @@ func parseAddress(addr string) (string, uint16) {
 
 	ipHex := parts[0]
 	ip := parseIPv4Hex(ipHex)
+	if isIPv6Address(addr) {
+		ip = parseIPv6Hex(ipHex)
+	}
 
 	return ip, uint16(port)
 }
 
+// parseQueues splits the "tx_queue:rx_queue" column. For LISTEN sockets the
+// kernel reports the accept backlog limit as tx_queue and the number of
+// connections waiting to be accepted as rx_queue.
+func parseQueues(queues string) (uint32, uint32) {
+	txHex, rxHex, found := strings.Cut(queues, ":")
+	if !found {
+		return 0, 0
+	}
+
+	tx, _ := strconv.ParseUint(txHex, 16, 32)
+	rx, _ := strconv.ParseUint(rxHex, 16, 32)
+
+	return uint32(tx), uint32(rx)
+}
+
+func isIPv6Address(addr string) bool {
+	ipHex, _, found := strings.Cut(addr, ":")
+	return found && len(ipHex) == 32
+}
+
 func parseIPv4Hex(hexIP string) string {
 	if len(hexIP) != 8 {
 		return ""
@@ -112,3 +146,25 @@ func parseIPv4Hex(hexIP string) string {
 
 	return fmt.Sprintf("%d.%d.%d.%d", bytes[3], bytes[2], bytes[1], bytes[0])
 }
+
+// parseIPv6Hex decodes the /proc/net/tcp6 address format, which stores the
+// address as four 32-bit words in host byte order.
+func parseIPv6Hex(hexIP string) string {
+	if len(hexIP) != 32 {
+		return ""
+	}
+
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil {
+		return ""
+	}
+
+	ip := make(net.IP, net.IPv6len)
+	for word := 0; word < 4; word++ {
+		for i := 0; i < 4; i++ {
+			ip[word*4+i] = raw[word*4+3-i]
+		}
+	}
+
+	return ip.String()
+}
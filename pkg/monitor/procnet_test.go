@@ -40,6 +40,7 @@ func TestParseProcNetTCP_ValidFile(t *testing.T) {
 	assert.Equal(t, "127.0.0.1", conn.LocalAddr)
 	assert.Equal(t, uint16(8080), conn.LocalPort)
 	assert.Equal(t, StateListen, conn.State)
+	assert.Equal(t, uint64(12345), conn.Inode)
 }
 
 func TestParseProcNetTCP_EmptyFile(t *testing.T) {
@@ -203,3 +204,68 @@ func TestParseProcNetTCP_ScannerError(t *testing.T) {
 	assert.Contains(t, err.Error(), "token too long")
 	assert.Empty(t, conns)
 }
+
+func TestParseAddress_IPv6(t *testing.T) {
+	tests := []struct {
+		name     string
+		addr     string
+		wantIP   string
+		wantPort uint16
+	}{
+		{"loopback port 8080", "00000000000000000000000001000000:1F90", "::1", 8080},
+		{"v4-mapped port 443", "0000000000000000FFFF00000100007F:01BB", "127.0.0.1", 443},
+		{"unspecified port 80", "00000000000000000000000000000000:0050", "::", 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotIP, gotPort := parseAddress(tt.addr)
+			assert.Equal(t, tt.wantIP, gotIP)
+			assert.Equal(t, tt.wantPort, gotPort)
+		})
+	}
+}
+
+func TestParseIPv6Hex_Invalid(t *testing.T) {
+	assert.Equal(t, "", parseIPv6Hex("0100007F"))
+	assert.Equal(t, "", parseIPv6Hex("ZZZZZZZZZZZZZZZZZZZZZZZZZZZZZZZZ"))
+}
+
+func TestParseProcNetTCP_IPv6Flag(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "tcp6")
+
+	content := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000001000000:1F90 00000000000000000000000001000000:D431 01 00000000:00000000 00:00000000 00000000     0        0 12345 1 0000000000000000 20 4 30 10 -1`
+
+	err := os.WriteFile(tmpFile, []byte(content), 0644)
+	assert.NoError(t, err)
+
+	conns, err := parseProcNetTCP(tmpFile)
+	assert.NoError(t, err)
+	assert.Len(t, conns, 1)
+	assert.True(t, conns[0].IPv6)
+	assert.Equal(t, "::1", conns[0].LocalAddr)
+	assert.Equal(t, uint16(54321), conns[0].RemotePort)
+}
+
+func TestParseQueues(t *testing.T) {
+	tests := []struct {
+		name   string
+		queues string
+		wantTx uint32
+		wantRx uint32
+	}{
+		{"empty queues", "00000000:00000000", 0, 0},
+		{"listen backlog", "00000080:00000003", 128, 3},
+		{"malformed", "00000080", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, rx := parseQueues(tt.queues)
+			assert.Equal(t, tt.wantTx, tx)
+			assert.Equal(t, tt.wantRx, rx)
+		})
+	}
+}
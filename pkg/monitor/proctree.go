@@ -0,0 +1,133 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var ownedSocketInodes = ownedSocketInodesImpl
+
+func ownedSocketInodesImpl(rootPID int) (map[uint64]bool, error) {
+	return socketInodesForTree("/proc", rootPID)
+}
+
+// socketInodesForTree collects the inodes of every socket held open by
+// rootPID and its descendants.
+func socketInodesForTree(procRoot string, rootPID int) (map[uint64]bool, error) {
+	pids, err := processTree(procRoot, rootPID)
+	if err != nil {
+		return nil, err
+	}
+
+	inodes := make(map[uint64]bool)
+	for _, pid := range pids {
+		collectSocketInodes(procRoot, pid, inodes)
+	}
+
+	return inodes, nil
+}
+
+func processTree(procRoot string, rootPID int) ([]int, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	children := make(map[int][]int)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		ppid, err := readParentPID(procRoot, pid)
+		if err != nil {
+			continue
+		}
+
+		children[ppid] = append(children[ppid], pid)
+	}
+
+	tree := []int{rootPID}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i]]...)
+	}
+
+	return tree, nil
+}
+
+func readParentPID(procRoot string, pid int) (int, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, err
+	}
+
+	// The command name is wrapped in parentheses and may itself contain
+	// spaces or parentheses, so fields are counted after the last ')'.
+	stat := string(data)
+	commEnd := strings.LastIndex(stat, ")")
+	if commEnd < 0 {
+		return 0, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+
+	fields := strings.Fields(stat[commEnd+1:])
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+
+	return strconv.Atoi(fields[1])
+}
+
+func collectSocketInodes(procRoot string, pid int, inodes map[uint64]bool) {
+	fdDir := filepath.Join(procRoot, strconv.Itoa(pid), "fd")
+
+	entries, err := os.ReadDir(fdDir)
+	if err != nil {
+		slog.Debug("Unable to read process file descriptors", "pid", pid, "error", err)
+		return
+	}
+
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(fdDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		inode, ok := parseSocketLink(target)
+		if ok {
+			inodes[inode] = true
+		}
+	}
+}
+
+func parseSocketLink(target string) (uint64, bool) {
+	inodeStr, found := strings.CutPrefix(target, "socket:[")
+	if !found {
+		return 0, false
+	}
+
+	inode, err := strconv.ParseUint(strings.TrimSuffix(inodeStr, "]"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return inode, true
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFakeProcess(t *testing.T, procRoot string, pid int, ppid int, comm string, fds map[string]string) {
+	pidDir := filepath.Join(procRoot, strconv.Itoa(pid))
+	fdDir := filepath.Join(pidDir, "fd")
+	assert.NoError(t, os.MkdirAll(fdDir, 0755))
+
+	stat := fmt.Sprintf("%d (%s) S %d %d %d 0 -1 4194560", pid, comm, ppid, pid, pid)
+	assert.NoError(t, os.WriteFile(filepath.Join(pidDir, "stat"), []byte(stat), 0644))
+
+	for fd, target := range fds {
+		assert.NoError(t, os.Symlink(target, filepath.Join(fdDir, fd)))
+	}
+}
+
+func TestSocketInodesForTree(t *testing.T) {
+	procRoot := t.TempDir()
+
+	writeFakeProcess(t, procRoot, 100, 1, "app", map[string]string{
+		"0": "/dev/null",
+		"3": "socket:[1001]",
+	})
+	writeFakeProcess(t, procRoot, 101, 100, "worker (1)", map[string]string{
+		"4": "socket:[1002]",
+		"5": "pipe:[77]",
+	})
+	writeFakeProcess(t, procRoot, 102, 101, "grandchild", map[string]string{
+		"6": "socket:[1003]",
+	})
+	writeFakeProcess(t, procRoot, 200, 1, "sidecar", map[string]string{
+		"3": "socket:[2001]",
+	})
+	assert.NoError(t, os.MkdirAll(filepath.Join(procRoot, "net"), 0755))
+
+	inodes, err := socketInodesForTree(procRoot, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint64]bool{1001: true, 1002: true, 1003: true}, inodes)
+}
+
+func TestSocketInodesForTree_MissingProcRoot(t *testing.T) {
+	_, err := socketInodesForTree("/nonexistent/proc", 100)
+	assert.Error(t, err)
+}
+
+func TestReadParentPID_Malformed(t *testing.T) {
+	procRoot := t.TempDir()
+	pidDir := filepath.Join(procRoot, "42")
+	assert.NoError(t, os.MkdirAll(pidDir, 0755))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(pidDir, "stat"), []byte("42 no-parens"), 0644))
+	_, err := readParentPID(procRoot, 42)
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(pidDir, "stat"), []byte("42 (app) S"), 0644))
+	_, err = readParentPID(procRoot, 42)
+	assert.Error(t, err)
+}
+
+func TestParseSocketLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		wantInode uint64
+		wantOK    bool
+	}{
+		{"socket", "socket:[12345]", 12345, true},
+		{"pipe", "pipe:[12345]", 0, false},
+		{"file", "/var/log/app.log", 0, false},
+		{"malformed", "socket:[abc]", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inode, ok := parseSocketLink(tt.target)
+			assert.Equal(t, tt.wantInode, inode)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestOwnedSocketInodes_CurrentProcess(t *testing.T) {
+	_, err := ownedSocketInodes(os.Getpid())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"bufio"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	UnixTypeStream    uint16 = 0x0001
+	UnixTypeDgram     uint16 = 0x0002
+	UnixTypeSeqPacket uint16 = 0x0005
+
+	UnixStateUnconnected   uint8 = 0x01
+	UnixStateConnecting    uint8 = 0x02
+	UnixStateConnected     uint8 = 0x03
+	UnixStateDisconnecting uint8 = 0x04
+)
+
+// UnixSocket is a row of /proc/net/unix. Sockets accepted from a listener
+// report the listener's path, so server-side connections can be matched to
+// the path the application is bound to.
+type UnixSocket struct {
+	Path  string
+	Type  uint16
+	State uint8
+	Inode uint64
+}
+
+var parseProcNetUnix = parseProcNetUnixImpl
+
+func parseProcNetUnixImpl(path string) ([]UnixSocket, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		slog.Error("Failed to open proc net file", "path", path, "error", err)
+		return nil, err
+	}
+	defer file.Close()
+
+	var sockets []UnixSocket
+	scanner := bufio.NewScanner(file)
+
+	scanner.Scan()
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) < 7 {
+			slog.Debug("Skipping malformed line in proc net file", "path", path, "line_number", lineNum, "field_count", len(fields))
+			continue
+		}
+
+		socketType, _ := strconv.ParseUint(fields[4], 16, 16)
+		state, _ := strconv.ParseUint(fields[5], 16, 8)
+		inode, _ := strconv.ParseUint(fields[6], 10, 64)
+
+		socket := UnixSocket{
+			Type:  uint16(socketType),
+			State: uint8(state),
+			Inode: inode,
+		}
+
+		if len(fields) > 7 {
+			socket.Path = strings.Join(fields[7:], " ")
+		}
+
+		sockets = append(sockets, socket)
+	}
+
+	if err := scanner.Err(); err != nil {
+		slog.Error("Error reading proc net file", "path", path, "error", err)
+		return sockets, err
+	}
+
+	slog.Debug("Parsed proc net file", "path", path, "socket_count", len(sockets))
+	return sockets, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProcNetUnix_ValidFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "unix")
+
+	content := `Num       RefCount Protocol Flags    Type St Inode Path
+0000000000000000: 00000002 00000000 00010000 0001 01 11111 /run/app.sock
+0000000000000000: 00000003 00000000 00000000 0001 03 22222 /run/app.sock
+0000000000000000: 00000003 00000000 00000000 0001 03 33333
+0000000000000000: 00000002 00000000 00000000 0002 01 44444 @abstract name
+0000000000000000: short`
+
+	err := os.WriteFile(tmpFile, []byte(content), 0644)
+	assert.NoError(t, err)
+
+	sockets, err := parseProcNetUnix(tmpFile)
+	assert.NoError(t, err)
+	assert.Len(t, sockets, 4)
+
+	assert.Equal(t, UnixSocket{Path: "/run/app.sock", Type: UnixTypeStream, State: UnixStateUnconnected, Inode: 11111}, sockets[0])
+	assert.Equal(t, UnixSocket{Path: "/run/app.sock", Type: UnixTypeStream, State: UnixStateConnected, Inode: 22222}, sockets[1])
+	assert.Equal(t, "", sockets[2].Path)
+	assert.Equal(t, "@abstract name", sockets[3].Path)
+	assert.Equal(t, UnixTypeDgram, sockets[3].Type)
+}
+
+func TestParseProcNetUnix_InvalidFile(t *testing.T) {
+	_, err := parseProcNetUnix("/nonexistent/file")
+	assert.Error(t, err)
+}
+
+func TestParseProcNetUnix_RealFile(t *testing.T) {
+	_, err := parseProcNetUnix("/proc/net/unix")
+	if err != nil {
+		t.Skipf("/proc/net/unix not accessible: %v", err)
+	}
+}
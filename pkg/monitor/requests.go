@@ -0,0 +1,41 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+// RequestCounter reports requests, or connections for a TCP proxy, that are
+// still being served on a port.
+type RequestCounter interface {
+	InFlight() int
+}
+
+// SetRequestCounter counts in-flight requests instead of sockets on port.
+// It is used when a proxy forwards to the application on that port, because
+// the proxy's idle keep-alive connections would otherwise hold up a drain.
+func (m *Monitor) SetRequestCounter(port uint16, counter RequestCounter) {
+	m.requestCounterPort = port
+	m.requestCounter = counter
+}
+
+func (m *Monitor) isRequestCountedPort(port uint16) bool {
+	return m.requestCounter != nil && port == m.requestCounterPort
+}
+
+func (m *Monitor) countInFlightRequests() int {
+	if m.requestCounter == nil {
+		return 0
+	}
+
+	return m.requestCounter.InFlight()
+}
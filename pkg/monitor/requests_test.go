@@ -0,0 +1,64 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRequestCounter struct {
+	inFlight int
+}
+
+func (f *fakeRequestCounter) InFlight() int {
+	return f.inFlight
+}
+
+func TestMonitor_CountActiveConnections_RequestCounter(t *testing.T) {
+	mockProcNet(t, []Connection{
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "127.0.0.1", RemotePort: 50000},
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "127.0.0.1", RemotePort: 50001},
+		{LocalPort: 9090, State: StateEstablished, RemoteAddr: "10.0.0.1", RemotePort: 50002},
+	}, []UnixSocket{})
+
+	counter := &fakeRequestCounter{inFlight: 0}
+	m := NewMonitor([]uint16{8080, 9090}, 1*time.Second)
+	m.SetRequestCounter(8080, counter)
+
+	count, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count, "idle proxy connections on the app port are not counted")
+
+	counter.inFlight = 3
+	count, err = m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
+
+func TestMonitor_WaitForZeroConnections_RequestCounter(t *testing.T) {
+	mockProcNet(t, []Connection{
+		{LocalPort: 8080, State: StateEstablished, RemoteAddr: "127.0.0.1", RemotePort: 50000},
+	}, []UnixSocket{})
+
+	m := NewMonitor([]uint16{8080}, 10*time.Millisecond)
+	m.SetRequestCounter(8080, &fakeRequestCounter{inFlight: 0})
+
+	err := m.WaitForZeroConnections(context.Background(), 500*time.Millisecond)
+	assert.NoError(t, err)
+}
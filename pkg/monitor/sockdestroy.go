@@ -0,0 +1,139 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	inetDiagReqV2Len = 56
+	nlmsgErrorLen    = 4
+	inetDiagNoCookie = 0xffffffff
+	allTCPStates     = 0xffffffff
+)
+
+var destroySocket = destroySocketImpl
+
+// destroySocketImpl closes a TCP socket with a netlink SOCK_DESTROY request.
+// It requires CAP_NET_ADMIN and a kernel built with CONFIG_INET_DIAG_DESTROY.
+func destroySocketImpl(conn Connection) error {
+	req, err := buildSockDestroyRequest(conn)
+	if err != nil {
+		return err
+	}
+
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_SOCK_DIAG)
+	if err != nil {
+		return fmt.Errorf("failed to open sock_diag socket: %w", err)
+	}
+	defer unix.Close(fd)
+
+	timeout := unix.Timeval{Sec: 1}
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		return fmt.Errorf("failed to set sock_diag receive timeout: %w", err)
+	}
+
+	if err := unix.Sendto(fd, req, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return fmt.Errorf("failed to send SOCK_DESTROY request: %w", err)
+	}
+
+	resp := make([]byte, unix.Getpagesize())
+	n, _, err := unix.Recvfrom(fd, resp, 0)
+	if err != nil {
+		return fmt.Errorf("failed to read SOCK_DESTROY response: %w", err)
+	}
+
+	return parseNetlinkAck(resp[:n])
+}
+
+func buildSockDestroyRequest(conn Connection) ([]byte, error) {
+	family := uint8(unix.AF_INET)
+	addrLen := net.IPv4len
+	if conn.IPv6 {
+		family = unix.AF_INET6
+		addrLen = net.IPv6len
+	}
+
+	src, err := socketAddressBytes(conn.LocalAddr, addrLen)
+	if err != nil {
+		return nil, err
+	}
+
+	dst, err := socketAddressBytes(conn.RemoteAddr, addrLen)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := make([]byte, unix.SizeofNlMsghdr+inetDiagReqV2Len)
+
+	binary.NativeEndian.PutUint32(msg[0:4], uint32(len(msg)))
+	binary.NativeEndian.PutUint16(msg[4:6], unix.SOCK_DESTROY)
+	binary.NativeEndian.PutUint16(msg[6:8], unix.NLM_F_REQUEST|unix.NLM_F_ACK)
+
+	req := msg[unix.SizeofNlMsghdr:]
+	req[0] = family
+	req[1] = unix.IPPROTO_TCP
+	binary.NativeEndian.PutUint32(req[4:8], allTCPStates)
+
+	binary.BigEndian.PutUint16(req[8:10], conn.LocalPort)
+	binary.BigEndian.PutUint16(req[10:12], conn.RemotePort)
+	copy(req[12:28], src)
+	copy(req[28:44], dst)
+	binary.NativeEndian.PutUint32(req[48:52], inetDiagNoCookie)
+	binary.NativeEndian.PutUint32(req[52:56], inetDiagNoCookie)
+
+	return msg, nil
+}
+
+func socketAddressBytes(addr string, addrLen int) ([]byte, error) {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid socket address: %q", addr)
+	}
+
+	if addrLen == net.IPv4len {
+		ip = ip.To4()
+		if ip == nil {
+			return nil, fmt.Errorf("not an IPv4 address: %q", addr)
+		}
+		return ip, nil
+	}
+
+	return ip.To16(), nil
+}
+
+func parseNetlinkAck(resp []byte) error {
+	if len(resp) < unix.SizeofNlMsghdr+nlmsgErrorLen {
+		return fmt.Errorf("short netlink response: %d bytes", len(resp))
+	}
+
+	msgType := binary.NativeEndian.Uint16(resp[4:6])
+	if msgType != unix.NLMSG_ERROR {
+		return fmt.Errorf("unexpected netlink message type: %d", msgType)
+	}
+
+	code := int32(binary.NativeEndian.Uint32(resp[unix.SizeofNlMsghdr : unix.SizeofNlMsghdr+nlmsgErrorLen]))
+	if code == 0 {
+		return nil
+	}
+
+	return syscall.Errno(-code)
+}
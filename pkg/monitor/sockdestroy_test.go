@@ -0,0 +1,89 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func TestBuildSockDestroyRequest_IPv4(t *testing.T) {
+	conn := Connection{
+		LocalAddr:  "10.0.0.1",
+		LocalPort:  8080,
+		RemoteAddr: "192.168.1.20",
+		RemotePort: 40000,
+	}
+
+	msg, err := buildSockDestroyRequest(conn)
+	assert.NoError(t, err)
+	assert.Len(t, msg, unix.SizeofNlMsghdr+inetDiagReqV2Len)
+
+	assert.Equal(t, uint32(len(msg)), binary.NativeEndian.Uint32(msg[0:4]))
+	assert.Equal(t, uint16(unix.SOCK_DESTROY), binary.NativeEndian.Uint16(msg[4:6]))
+
+	req := msg[unix.SizeofNlMsghdr:]
+	assert.Equal(t, uint8(unix.AF_INET), req[0])
+	assert.Equal(t, uint8(unix.IPPROTO_TCP), req[1])
+	assert.Equal(t, uint16(8080), binary.BigEndian.Uint16(req[8:10]))
+	assert.Equal(t, uint16(40000), binary.BigEndian.Uint16(req[10:12]))
+	assert.Equal(t, []byte{10, 0, 0, 1}, req[12:16])
+	assert.Equal(t, []byte{192, 168, 1, 20}, req[28:32])
+}
+
+func TestBuildSockDestroyRequest_IPv6(t *testing.T) {
+	conn := Connection{
+		LocalAddr:  "127.0.0.1",
+		LocalPort:  8080,
+		RemoteAddr: "127.0.0.1",
+		RemotePort: 40000,
+		IPv6:       true,
+	}
+
+	msg, err := buildSockDestroyRequest(conn)
+	assert.NoError(t, err)
+
+	req := msg[unix.SizeofNlMsghdr:]
+	assert.Equal(t, uint8(unix.AF_INET6), req[0])
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 127, 0, 0, 1}, req[12:28])
+}
+
+func TestBuildSockDestroyRequest_InvalidAddress(t *testing.T) {
+	_, err := buildSockDestroyRequest(Connection{LocalAddr: "", RemoteAddr: "10.0.0.1"})
+	assert.Error(t, err)
+
+	_, err = buildSockDestroyRequest(Connection{LocalAddr: "::1", RemoteAddr: "::1"})
+	assert.Error(t, err)
+}
+
+func netlinkAck(code int32) []byte {
+	resp := make([]byte, unix.SizeofNlMsghdr+nlmsgErrorLen)
+	binary.NativeEndian.PutUint16(resp[4:6], unix.NLMSG_ERROR)
+	binary.NativeEndian.PutUint32(resp[unix.SizeofNlMsghdr:], uint32(code))
+	return resp
+}
+
+func TestParseNetlinkAck(t *testing.T) {
+	assert.NoError(t, parseNetlinkAck(netlinkAck(0)))
+	assert.ErrorIs(t, parseNetlinkAck(netlinkAck(-int32(unix.ENOENT))), unix.ENOENT)
+	assert.Error(t, parseNetlinkAck([]byte{1, 2, 3}))
+
+	wrongType := netlinkAck(0)
+	binary.NativeEndian.PutUint16(wrongType[4:6], unix.NLMSG_DONE)
+	assert.Error(t, parseNetlinkAck(wrongType))
+}
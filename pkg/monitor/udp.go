@@ -0,0 +1,146 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+// UDPSignal selects the heuristic used to decide whether a UDP port is still
+// serving traffic, since UDP sockets carry no connection state.
+type UDPSignal string
+
+const (
+	// UDPSignalQueues counts sockets that have datagrams waiting in their
+	// send or receive queue.
+	UDPSignalQueues UDPSignal = "queues"
+
+	// UDPSignalConnected counts connected sockets, as created per flow by
+	// servers such as QUIC implementations that connect() each client, and
+	// which have been active within the idle timeout.
+	UDPSignalConnected UDPSignal = "connected"
+)
+
+// /proc/net/udp and /proc/net/udp6 share the /proc/net/tcp column layout;
+// connected sockets report state 01 and unconnected ones 07.
+var parseProcNetUDP = parseProcNetTCPImpl
+
+func (m *Monitor) SetUDPMonitoring(ports []uint16, signal UDPSignal, idleTimeout time.Duration) {
+	m.udpPorts = ports
+	m.udpSignal = signal
+	m.udpIdleTimeout = idleTimeout
+}
+
+func (m *Monitor) countUDPFlows(ownedInodes map[uint64]bool) (int, error) {
+	if len(m.udpPorts) == 0 {
+		return 0, nil
+	}
+
+	udpConns, err := parseProcNetUDP("/proc/net/udp")
+	if err != nil {
+		slog.Error("Failed to parse /proc/net/udp", "error", err)
+		return 0, err
+	}
+
+	udp6Conns, err := parseProcNetUDP("/proc/net/udp6")
+	if err != nil {
+		slog.Error("Failed to parse /proc/net/udp6", "error", err)
+		return 0, err
+	}
+
+	allConns := append(udpConns, udp6Conns...)
+	now := time.Now()
+
+	byPort := make(map[uint16]int, len(m.udpPorts))
+	for _, port := range m.udpPorts {
+		byPort[port] = 0
+	}
+
+	var flows []Connection
+	for _, conn := range allConns {
+		isMonitored := slices.Contains(m.udpPorts, conn.LocalPort)
+		isOwned := ownedInodes == nil || ownedInodes[conn.Inode]
+
+		if isMonitored && isOwned {
+			flows = append(flows, conn)
+		}
+	}
+
+	lastActive := m.trackUDPActivity(flows, now)
+
+	total := 0
+	for _, conn := range flows {
+		if m.isActiveUDPFlow(conn, lastActive, now) {
+			byPort[conn.LocalPort]++
+			total++
+		}
+	}
+
+	for port, count := range byPort {
+		metrics.ActiveUDPFlows.WithLabelValues(strconv.Itoa(int(port))).Set(float64(count))
+	}
+
+	return total, nil
+}
+
+func (m *Monitor) isActiveUDPFlow(conn Connection, lastActive map[connectionKey]time.Time, now time.Time) bool {
+	hasQueuedData := conn.TxQueue > 0 || conn.RxQueue > 0
+
+	if m.udpSignal != UDPSignalConnected {
+		return hasQueuedData
+	}
+
+	isConnected := conn.State == StateEstablished
+	if !isConnected {
+		return false
+	}
+
+	idleTimeoutDisabled := m.udpIdleTimeout <= 0
+	if idleTimeoutDisabled {
+		return true
+	}
+
+	return now.Sub(lastActive[keyOf(conn)]) < m.udpIdleTimeout
+}
+
+// trackUDPActivity records when each flow was last seen with queued data.
+// A flow counts as active from the moment it first appears.
+func (m *Monitor) trackUDPActivity(flows []Connection, now time.Time) map[connectionKey]time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lastActive := make(map[connectionKey]time.Time, len(flows))
+
+	for _, conn := range flows {
+		key := keyOf(conn)
+
+		previous, known := m.udpLastActive[key]
+		hasQueuedData := conn.TxQueue > 0 || conn.RxQueue > 0
+
+		if !known || hasQueuedData {
+			previous = now
+		}
+
+		lastActive[key] = previous
+	}
+
+	m.udpLastActive = lastActive
+	return lastActive
+}
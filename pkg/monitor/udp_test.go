@@ -0,0 +1,154 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockProcNetUDP(t *testing.T, udp []Connection) {
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		return []Connection{}, nil
+	}
+
+	origParseProcNetUDP := parseProcNetUDP
+	parseProcNetUDP = func(path string) ([]Connection, error) {
+		if path != "/proc/net/udp" {
+			return []Connection{}, nil
+		}
+		return udp, nil
+	}
+
+	t.Cleanup(func() {
+		parseProcNetTCP = origParseProcNetTCP
+		parseProcNetUDP = origParseProcNetUDP
+	})
+}
+
+func TestMonitor_SetUDPMonitoring(t *testing.T) {
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+
+	m.SetUDPMonitoring([]uint16{443}, UDPSignalConnected, 10*time.Second)
+
+	assert.Equal(t, []uint16{443}, m.udpPorts)
+	assert.Equal(t, UDPSignalConnected, m.udpSignal)
+	assert.Equal(t, 10*time.Second, m.udpIdleTimeout)
+}
+
+func TestMonitor_CountActiveConnections_UDPQueues(t *testing.T) {
+	mockProcNetUDP(t, []Connection{
+		{LocalPort: 443, State: StateClose, RxQueue: 512},
+		{LocalPort: 443, State: StateClose},
+		{LocalPort: 443, State: StateEstablished, TxQueue: 64, RemoteAddr: "10.0.0.1", RemotePort: 5000},
+		{LocalPort: 53, State: StateClose, RxQueue: 128},
+	})
+
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetUDPMonitoring([]uint16{443}, UDPSignalQueues, 0)
+
+	count, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	var metric dto.Metric
+	assert.NoError(t, metrics.ActiveUDPFlows.WithLabelValues("443").Write(&metric))
+	assert.Equal(t, float64(2), metric.GetGauge().GetValue())
+
+	assert.NoError(t, metrics.ActiveConnections.Write(&metric))
+	assert.Equal(t, float64(0), metric.GetGauge().GetValue(), "UDP flows are reported separately from TCP")
+}
+
+func TestMonitor_CountActiveConnections_UDPConnected(t *testing.T) {
+	mockProcNetUDP(t, []Connection{
+		{LocalPort: 443, State: StateClose, RxQueue: 512},
+		{LocalPort: 443, State: StateEstablished, RemoteAddr: "10.0.0.1", RemotePort: 5000},
+		{LocalPort: 443, State: StateEstablished, RemoteAddr: "10.0.0.2", RemotePort: 5001},
+	})
+
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetUDPMonitoring([]uint16{443}, UDPSignalConnected, 0)
+
+	count, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestMonitor_CountActiveConnections_UDPConnectedIdleTimeout(t *testing.T) {
+	idle := Connection{LocalPort: 443, State: StateEstablished, RemoteAddr: "10.0.0.1", RemotePort: 5000}
+	busy := Connection{LocalPort: 443, State: StateEstablished, RemoteAddr: "10.0.0.2", RemotePort: 5001, RxQueue: 100}
+	mockProcNetUDP(t, []Connection{idle, busy})
+
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetUDPMonitoring([]uint16{443}, UDPSignalConnected, 20*time.Millisecond)
+
+	count, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count, "new flows count as active")
+
+	time.Sleep(30 * time.Millisecond)
+
+	count, err = m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count, "flows without queued data expire after the idle timeout")
+}
+
+func TestMonitor_CountActiveConnections_UDPParseErrors(t *testing.T) {
+	mockProcNetUDP(t, nil)
+
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetUDPMonitoring([]uint16{443}, UDPSignalQueues, 0)
+
+	parseProcNetUDP = func(path string) ([]Connection, error) {
+		return nil, os.ErrPermission
+	}
+	_, err := m.CountActiveConnections()
+	assert.Error(t, err)
+
+	parseProcNetUDP = func(path string) ([]Connection, error) {
+		if path == "/proc/net/udp6" {
+			return nil, os.ErrNotExist
+		}
+		return []Connection{}, nil
+	}
+	_, err = m.CountActiveConnections()
+	assert.Error(t, err)
+}
+
+func TestMonitor_CountActiveConnections_UDPDisabled(t *testing.T) {
+	mockProcNetUDP(t, nil)
+	parseProcNetUDP = func(path string) ([]Connection, error) {
+		t.Fatal("UDP sources should not be read when no UDP ports are configured")
+		return nil, nil
+	}
+
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+
+	_, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+}
+
+func TestParseProcNetUDP_RealFile(t *testing.T) {
+	_, err := parseProcNetUDP("/proc/net/udp")
+	if err != nil {
+		t.Skipf("/proc/net/udp not accessible: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+// SetUnixSocketPaths enables monitoring of connected stream sockets bound to
+// the given paths. Abstract sockets are written with a leading '@'.
+func (m *Monitor) SetUnixSocketPaths(paths []string) {
+	m.unixPaths = paths
+}
+
+func (m *Monitor) countUnixConnections(ownedInodes map[uint64]bool) (int, error) {
+	if len(m.unixPaths) == 0 {
+		return 0, nil
+	}
+
+	sockets, err := parseProcNetUnix("/proc/net/unix")
+	if err != nil {
+		slog.Error("Failed to parse /proc/net/unix", "error", err)
+		return 0, err
+	}
+
+	byPath := make(map[string]int, len(m.unixPaths))
+	for _, path := range m.unixPaths {
+		byPath[path] = 0
+	}
+
+	total := 0
+	for _, socket := range sockets {
+		isMonitored := slices.Contains(m.unixPaths, socket.Path)
+		isConnectedStream := socket.Type == UnixTypeStream && socket.State == UnixStateConnected
+		isOwned := ownedInodes == nil || ownedInodes[socket.Inode]
+
+		if isMonitored && isConnectedStream && isOwned {
+			byPath[socket.Path]++
+			total++
+		}
+	}
+
+	for path, count := range byPath {
+		metrics.ActiveUnixConnections.WithLabelValues(path).Set(float64(count))
+	}
+
+	return total, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockProcNet(t *testing.T, tcp []Connection, unix []UnixSocket) {
+	origParseProcNetTCP := parseProcNetTCP
+	parseProcNetTCP = func(path string) ([]Connection, error) {
+		if path != "/proc/net/tcp" {
+			return []Connection{}, nil
+		}
+		return tcp, nil
+	}
+
+	origParseProcNetUnix := parseProcNetUnix
+	parseProcNetUnix = func(path string) ([]UnixSocket, error) {
+		return unix, nil
+	}
+
+	t.Cleanup(func() {
+		parseProcNetTCP = origParseProcNetTCP
+		parseProcNetUnix = origParseProcNetUnix
+	})
+}
+
+func TestMonitor_CountActiveConnections_UnixSockets(t *testing.T) {
+	mockProcNet(t, []Connection{
+		{LocalPort: 8080, State: StateEstablished},
+	}, []UnixSocket{
+		{Path: "/run/app.sock", Type: UnixTypeStream, State: UnixStateUnconnected, Inode: 1},
+		{Path: "/run/app.sock", Type: UnixTypeStream, State: UnixStateConnected, Inode: 2},
+		{Path: "/run/app.sock", Type: UnixTypeStream, State: UnixStateConnected, Inode: 3},
+		{Path: "/run/app.sock", Type: UnixTypeDgram, State: UnixStateConnected, Inode: 4},
+		{Path: "/run/other.sock", Type: UnixTypeStream, State: UnixStateConnected, Inode: 5},
+		{Path: "", Type: UnixTypeStream, State: UnixStateConnected, Inode: 6},
+	})
+
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetUnixSocketPaths([]string{"/run/app.sock", "/run/idle.sock"})
+
+	count, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	var metric dto.Metric
+	assert.NoError(t, metrics.ActiveUnixConnections.WithLabelValues("/run/app.sock").Write(&metric))
+	assert.Equal(t, float64(2), metric.GetGauge().GetValue())
+
+	assert.NoError(t, metrics.ActiveUnixConnections.WithLabelValues("/run/idle.sock").Write(&metric))
+	assert.Equal(t, float64(0), metric.GetGauge().GetValue())
+}
+
+func TestMonitor_CountActiveConnections_UnixSocketsProcessTreeOnly(t *testing.T) {
+	mockProcNet(t, []Connection{}, []UnixSocket{
+		{Path: "/run/app.sock", Type: UnixTypeStream, State: UnixStateConnected, Inode: 2},
+		{Path: "/run/app.sock", Type: UnixTypeStream, State: UnixStateConnected, Inode: 3},
+	})
+
+	origOwnedSocketInodes := ownedSocketInodes
+	ownedSocketInodes = func(rootPID int) (map[uint64]bool, error) {
+		return map[uint64]bool{3: true}, nil
+	}
+	defer func() {
+		ownedSocketInodes = origOwnedSocketInodes
+	}()
+
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetUnixSocketPaths([]string{"/run/app.sock"})
+	m.SetProcessTreeOnly(true)
+	m.SetAppProcess(&os.Process{Pid: 100})
+
+	count, err := m.CountActiveConnections()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestMonitor_CountActiveConnections_UnixParseError(t *testing.T) {
+	mockProcNet(t, []Connection{}, nil)
+	parseProcNetUnix = func(path string) ([]UnixSocket, error) {
+		return nil, os.ErrPermission
+	}
+
+	m := NewMonitor([]uint16{8080}, 1*time.Second)
+	m.SetUnixSocketPaths([]string{"/run/app.sock"})
+
+	_, err := m.CountActiveConnections()
+	assert.Error(t, err)
+}
+
+func TestMonitor_WaitForZeroConnections_UnixSockets(t *testing.T) {
+	calls := 0
+	mockProcNet(t, []Connection{}, nil)
+	parseProcNetUnix = func(path string) ([]UnixSocket, error) {
+		calls++
+		if calls < 3 {
+			return []UnixSocket{{Path: "/run/app.sock", Type: UnixTypeStream, State: UnixStateConnected}}, nil
+		}
+		return []UnixSocket{}, nil
+	}
+
+	m := NewMonitor([]uint16{8080}, 10*time.Millisecond)
+	m.SetUnixSocketPaths([]string{"/run/app.sock"})
+
+	err := m.WaitForZeroConnections(context.Background(), 1*time.Second)
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, calls, 3)
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ActivationShell runs listenPIDWrapper, so socket activation needs it in the
+// container image.
+const ActivationShell = "/bin/sh"
+
+// listenPIDWrapper sets LISTEN_PID to the PID the application will run as.
+// The PID is only known after fork, so a shell exports its own PID and then
+// execs the application in its place.
+const listenPIDWrapper = `export LISTEN_PID=$$; exec "$0" "$@"`
+
+// OpenListeners binds a TCP listener for each port and returns the sockets as
+// files that can be passed to the application.
+func OpenListeners(ports []uint16) ([]*os.File, error) {
+	files := make([]*os.File, 0, len(ports))
+
+	for _, port := range ports {
+		file, err := openListener(port)
+		if err != nil {
+			closeFiles(files)
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+func openListener(port uint16) (*os.File, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on port %d: %w", port, err)
+	}
+	defer listener.Close()
+
+	// File returns a duplicate descriptor, which keeps the socket listening
+	// after the Go listener is closed.
+	file, err := listener.(*net.TCPListener).File()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listener file for port %d: %w", port, err)
+	}
+
+	return file, nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
+
+// SetInheritedListeners passes files to every application instance using
+// the systemd socket activation convention, so the sockets keep accepting
+// into the kernel backlog while the application restarts. The shutdown
+// coordinator closes them when it stops the application for good.
+func (m *Manager) SetInheritedListeners(files []*os.File, names []string) {
+	m.listenerFiles = files
+	m.listenerNames = names
+}
+
+func (m *Manager) appCommandLine() []string {
+	command := m.config.GetAppCommand()
+
+	if len(m.listenerFiles) == 0 {
+		return command
+	}
+
+	return append([]string{ActivationShell, "-c", listenPIDWrapper}, command...)
+}
+
+func (m *Manager) appEnvironment() []string {
+	var env []string
+
+	if len(m.listenerFiles) > 0 {
+		env = append(env,
+			"LISTEN_FDS="+strconv.Itoa(len(m.listenerFiles)),
+			"LISTEN_FDNAMES="+strings.Join(m.listenerNames, ":"),
+		)
+	}
+
+	if m.notifySocket != nil {
+		env = append(env, m.notifySocket.Environment()...)
+	}
+
+	env = append(env, m.extraEnv...)
+
+	if len(env) == 0 {
+		return nil
+	}
+
+	return append(os.Environ(), env...)
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenListeners(t *testing.T) {
+	ports := []uint16{getAvailablePort(), getAvailablePort()}
+
+	files, err := OpenListeners(ports)
+	assert.NoError(t, err)
+	defer closeFiles(files)
+
+	assert.Len(t, files, 2)
+
+	for _, port := range ports {
+		conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), time.Second)
+		assert.NoError(t, err, "listener should accept into the backlog without anyone calling accept")
+		conn.Close()
+	}
+}
+
+func TestOpenListeners_PortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	busyPort := uint16(listener.Addr().(*net.TCPAddr).Port)
+
+	files, err := OpenListeners([]uint16{getAvailablePort(), busyPort})
+	assert.Error(t, err)
+	assert.Nil(t, files)
+}
+
+func TestManager_appCommandLine(t *testing.T) {
+	manager := NewManager(&mockConfig{command: []string{"server", "--port", "8080"}})
+
+	assert.Equal(t, []string{"server", "--port", "8080"}, manager.appCommandLine())
+	assert.Nil(t, manager.appEnvironment(), "without listeners the environment is inherited as is")
+
+	manager.SetInheritedListeners([]*os.File{os.Stdin}, []string{"http"})
+
+	assert.Equal(t, []string{"/bin/sh", "-c", listenPIDWrapper, "server", "--port", "8080"}, manager.appCommandLine())
+	assert.Contains(t, manager.appEnvironment(), "LISTEN_FDS=1")
+	assert.Contains(t, manager.appEnvironment(), "LISTEN_FDNAMES=http")
+}
+
+func TestManager_startApp_PassesListeners(t *testing.T) {
+	files, err := OpenListeners([]uint16{getAvailablePort(), getAvailablePort()})
+	assert.NoError(t, err)
+	defer closeFiles(files)
+
+	script := `test "$LISTEN_PID" = "$$" && test "$LISTEN_FDS" = 2 && test "$LISTEN_FDNAMES" = "http:admin" && test -S /proc/self/fd/3 && test -S /proc/self/fd/4`
+	manager := NewManager(&mockConfig{command: []string{"sh", "-c", script}})
+	manager.connMonitor = &mockConnectionMonitor{}
+	manager.shutdownCoord = &mockShutdownCoordinator{}
+	manager.SetInheritedListeners(files, []string{"http", "admin"})
+
+	err = manager.startApp()
+	assert.NoError(t, err)
+
+	<-manager.appExit.Done()
+	state, err := manager.appExit.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, state.ExitCode(), "application should see the socket activation environment")
+}
+
+type mockNotifySocket struct {
+	resets int
+}
+
+func (m *mockNotifySocket) Environment() []string {
+	return []string{"NOTIFY_SOCKET=/tmp/notify.sock"}
+}
+
+func (m *mockNotifySocket) Reset() {
+	m.resets++
+}
+
+func TestManager_startApp_NotifySocket(t *testing.T) {
+	notifySocket := &mockNotifySocket{}
+	manager := NewManager(&mockConfig{command: []string{"sh", "-c", `test "$NOTIFY_SOCKET" = /tmp/notify.sock`}})
+	manager.connMonitor = &mockConnectionMonitor{}
+	manager.shutdownCoord = &mockShutdownCoordinator{}
+	manager.SetNotifySocket(notifySocket)
+
+	err := manager.startApp()
+	assert.NoError(t, err)
+
+	<-manager.appExit.Done()
+	state, err := manager.appExit.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, state.ExitCode(), "application should see NOTIFY_SOCKET")
+	assert.Equal(t, 1, notifySocket.resets, "notify state should be reset before the application starts")
+}
+
+func TestManager_appEnvironment_Extra(t *testing.T) {
+	manager := NewManager(&mockConfig{command: []string{"server"}})
+	manager.SetEnvironment([]string{"ZEROHALT_DRAIN_FILE=/tmp/draining"})
+
+	env := manager.appEnvironment()
+
+	assert.Contains(t, env, "ZEROHALT_DRAIN_FILE=/tmp/draining")
+	assert.Greater(t, len(env), 1, "extra variables are added to the inherited environment")
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// AppExit waits for one application instance to exit. It is the only caller
+// of Wait for that instance, so a restart and a shutdown can both wait for
+// the exit without racing each other for its status.
+type AppExit struct {
+	done  chan struct{}
+	state *os.ProcessState
+	err   error
+}
+
+// WatchExit starts waiting for process to exit.
+func WatchExit(process *os.Process) *AppExit {
+	exit := &AppExit{done: make(chan struct{})}
+
+	go func() {
+		exit.state, exit.err = process.Wait()
+		close(exit.done)
+	}()
+
+	return exit
+}
+
+// Done is closed once the process has exited.
+func (e *AppExit) Done() <-chan struct{} {
+	return e.done
+}
+
+// Result returns the exit status once Done is closed. A process reaped
+// elsewhere, such as by the zombie reaper, has exited as well, but its status
+// is lost, so Result returns a nil state and no error.
+func (e *AppExit) Result() (*os.ProcessState, error) {
+	if errors.Is(e.err, syscall.ECHILD) {
+		return nil, nil
+	}
+	return e.state, e.err
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchExit(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "exit 3")
+	assert.NoError(t, cmd.Start())
+
+	exit := WatchExit(cmd.Process)
+
+	select {
+	case <-exit.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Done should close once the process exits")
+	}
+
+	state, err := exit.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, state.ExitCode())
+}
+
+func TestWatchExit_ReapedElsewhere(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "exit 0")
+	assert.NoError(t, cmd.Start())
+
+	var status syscall.WaitStatus
+	_, err := syscall.Wait4(cmd.Process.Pid, &status, 0, nil)
+	assert.NoError(t, err)
+
+	exit := WatchExit(cmd.Process)
+
+	select {
+	case <-exit.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("a process reaped elsewhere has exited")
+	}
+
+	state, err := exit.Result()
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
@@ -15,17 +15,26 @@
 package process
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/jpasei/zerohalt/pkg/health"
+	"github.com/jpasei/zerohalt/pkg/hooks"
 	"github.com/jpasei/zerohalt/pkg/metrics"
 )
 
+// ErrShutdownCancelled is returned by a ShutdownCoordinator whose drain was
+// cancelled before the application was signalled. The manager then keeps
+// running the application.
+var ErrShutdownCancelled = errors.New("shutdown cancelled")
+
 type Config interface {
 	GetAppCommand() []string
 	GetAppPort() uint16
@@ -36,12 +45,12 @@ type Config interface {
 	GetHealthProbeInterval() time.Duration
 	GetShutdownConfig() ShutdownConfig
 	GetSignalConfig() SignalConfig
-	GetConnectionCheckInterval() interface{}
+	GetConnectionCheckInterval() time.Duration
 }
 
 type ShutdownConfig interface {
-	GetDrainTimeout() interface{}
-	GetShutdownTimeout() interface{}
+	GetDrainTimeout() time.Duration
+	GetShutdownTimeout() time.Duration
 	GetSignalToApp() string
 	GetForceKillAfterTimeout() bool
 }
@@ -50,33 +59,73 @@ type HealthServer interface {
 	Start() error
 	SetState(state health.HealthState)
 	GetState() health.HealthState
-	WaitForAppHealthy(timeout time.Duration, interval time.Duration) bool
+	WaitForAppHealthy(ctx context.Context, timeout time.Duration, interval time.Duration) bool
 }
 
 type ConnectionMonitor interface {
 	CountActiveConnections() (int, error)
-	WaitForZeroConnections(timeout interface{}) error
+	WaitForZeroConnections(ctx context.Context, timeout time.Duration) error
+	SetAppProcess(appProcess *os.Process)
 }
 
 type ShutdownCoordinator interface {
-	InitiateShutdown(sig os.Signal) error
-	SetAppProcess(appProcess *os.Process)
+	InitiateShutdown(ctx context.Context, sig os.Signal) error
+	HandleRepeatSignal(sig os.Signal)
+	CancelDrain(source string) error
+	SetAppProcess(appProcess *os.Process, exit *AppExit)
+	SetListenerFiles(files []*os.File)
+}
+
+// NotifySocket receives sd_notify messages from the application. It is reset
+// before every application instance starts.
+type NotifySocket interface {
+	Environment() []string
+	Reset()
 }
 
 type Manager struct {
 	config        Config
 	app           *exec.Cmd
+	appExit       *AppExit
 	healthServer  HealthServer
 	connMonitor   ConnectionMonitor
 	shutdownCoord ShutdownCoordinator
+	requestGate   RequestGate
+	listenerFiles []*os.File
+	listenerNames []string
+	notifySocket  NotifySocket
+	preStartHooks []hooks.Hook
+	extraEnv      []string
+
+	restartRequests chan chan error
+	restarting      atomic.Bool
+	stopped         chan struct{}
 }
 
 func NewManager(config Config) *Manager {
 	return &Manager{
-		config: config,
+		config:          config,
+		restartRequests: make(chan chan error),
+		stopped:         make(chan struct{}),
 	}
 }
 
+func (m *Manager) SetNotifySocket(notifySocket NotifySocket) {
+	m.notifySocket = notifySocket
+}
+
+// SetEnvironment adds KEY=VALUE variables to the application's environment.
+func (m *Manager) SetEnvironment(env []string) {
+	m.extraEnv = env
+}
+
+// SetPreStartHooks sets commands to run in order before the application
+// starts. The health state stays Starting while they run, and the first
+// failure stops startup.
+func (m *Manager) SetPreStartHooks(preStartHooks []hooks.Hook) {
+	m.preStartHooks = preStartHooks
+}
+
 func (m *Manager) Run(
 	healthServer HealthServer,
 	connMonitor ConnectionMonitor,
@@ -85,6 +134,7 @@ func (m *Manager) Run(
 	m.healthServer = healthServer
 	m.connMonitor = connMonitor
 	m.shutdownCoord = shutdownCoord
+	m.shutdownCoord.SetListenerFiles(m.listenerFiles)
 
 	metrics.HealthApp.Set(float64(health.StateStarting))
 
@@ -94,27 +144,21 @@ func (m *Manager) Run(
 
 	slog.Info("Health check server started", "port", m.config.GetHealthPort())
 
-	command := m.config.GetAppCommand()
-	if len(command) == 0 {
-		return fmt.Errorf("no application command specified")
-	}
-
-	m.app = exec.Command(command[0], command[1:]...)
-	m.app.Stdout = os.Stdout
-	m.app.Stderr = os.Stderr
-	m.app.Stdin = os.Stdin
+	signalConfig := m.config.GetSignalConfig()
+	signalHandler := NewSignalHandler(&signalConfig, nil)
+	sigChan := signalHandler.Setup()
+	slog.Info("Signal handler initialized and ready")
 
-	m.app.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true,
+	started, err := m.runPreStartHooks(sigChan, signalHandler)
+	if !started {
+		close(m.stopped)
+		return err
 	}
 
-	if err := m.app.Start(); err != nil {
-		return fmt.Errorf("failed to start application: %w", err)
+	if err := m.startApp(); err != nil {
+		return err
 	}
-
-	slog.Info("Application started", "pid", m.app.Process.Pid)
-
-	m.shutdownCoord.SetAppProcess(m.app.Process)
+	signalHandler.SetAppProcess(m.app.Process)
 
 	metrics.HealthApp.Set(float64(health.StateHealthy))
 
@@ -127,13 +171,9 @@ func (m *Manager) Run(
 		}
 	}()
 
-	signalConfig := m.config.GetSignalConfig()
-	signalHandler := NewSignalHandler(&signalConfig, m.app.Process)
-	sigChan := signalHandler.Setup()
-	slog.Info("Signal handler initialized and ready")
-
 	shutdownChan := make(chan error, 1)
 	go func() {
+		defer close(m.stopped)
 		shutdownChan <- m.handleSignals(sigChan, signalHandler)
 	}()
 
@@ -145,8 +185,82 @@ func (m *Manager) Run(
 	return <-shutdownChan
 }
 
+// runPreStartHooks runs the pre-start hooks while receiving signals. A
+// shutdown signal stops the running hook and abandons startup. It reports
+// whether the application should be started; err is set if a hook failed.
+// Zombies are not reaped meanwhile, since that could steal a hook's exit
+// status.
+func (m *Manager) runPreStartHooks(sigChan chan os.Signal, signalHandler *SignalHandler) (bool, error) {
+	if len(m.preStartHooks) == 0 {
+		return true, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hooks.RunAll(ctx, m.preStartHooks)
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return false, fmt.Errorf("pre-start hook failed: %w", err)
+			}
+			return true, nil
+
+		case sig := <-sigChan:
+			if signalHandler.Handle(sig) != ActionShutdown {
+				continue
+			}
+
+			slog.Warn("Shutdown signal received during pre-start hooks, not starting application", "signal", sig.String())
+			cancel()
+			<-done
+			return false, nil
+		}
+	}
+}
+
+func (m *Manager) startApp() error {
+	if len(m.config.GetAppCommand()) == 0 {
+		return fmt.Errorf("no application command specified")
+	}
+
+	command := m.appCommandLine()
+	app := exec.Command(command[0], command[1:]...)
+	app.Stdout = os.Stdout
+	app.Stderr = os.Stderr
+	app.Stdin = os.Stdin
+	app.Env = m.appEnvironment()
+	app.ExtraFiles = m.listenerFiles
+
+	app.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+
+	if m.notifySocket != nil {
+		m.notifySocket.Reset()
+	}
+
+	if err := app.Start(); err != nil {
+		return fmt.Errorf("failed to start application: %w", err)
+	}
+
+	m.app = app
+	m.appExit = WatchExit(app.Process)
+	slog.Info("Application started", "pid", m.app.Process.Pid)
+
+	m.shutdownCoord.SetAppProcess(m.app.Process, m.appExit)
+	m.connMonitor.SetAppProcess(m.app.Process)
+
+	return nil
+}
+
 func (m *Manager) waitForAppHealthy(startupTimeout time.Duration, probeInterval time.Duration) {
-	healthy := m.healthServer.WaitForAppHealthy(startupTimeout, probeInterval)
+	healthy := m.healthServer.WaitForAppHealthy(context.Background(), startupTimeout, probeInterval)
 
 	healthyState := health.StateUnhealthy
 	if healthy {
@@ -165,21 +279,115 @@ func (m *Manager) waitForAppHealthy(startupTimeout time.Duration, probeInterval
 
 func (m *Manager) handleSignals(sigChan chan os.Signal, signalHandler *SignalHandler) error {
 	for {
-		sig := <-sigChan
-		action := signalHandler.Handle(sig)
+		sig := m.waitForShutdownSignal(sigChan, signalHandler)
+
+		err := m.handleShutdown(sig, sigChan, signalHandler)
+		if errors.Is(err, ErrShutdownCancelled) {
+			continue
+		}
+		return err
+	}
+}
+
+// waitForShutdownSignal handles signals and restart requests until a
+// shutdown signal arrives, and returns that signal.
+func (m *Manager) waitForShutdownSignal(sigChan chan os.Signal, signalHandler *SignalHandler) os.Signal {
+	for {
+		select {
+		case reply := <-m.restartRequests:
+			if sig := m.handleRestart(reply, sigChan, signalHandler); sig != nil {
+				return sig
+			}
+
+		case sig := <-sigChan:
+			switch signalHandler.Handle(sig) {
+			case ActionShutdown:
+				return sig
+
+			case ActionCancelDrain:
+				m.shutdownCoord.CancelDrain("signal")
+
+			case ActionRestart:
+				if sig := m.handleRestart(nil, sigChan, signalHandler); sig != nil {
+					return sig
+				}
+
+			case ActionReapZombies:
+				m.reapZombies()
+			}
+		}
+	}
+}
 
-		switch action {
-		case ActionShutdown:
-			return m.shutdownCoord.InitiateShutdown(sig)
+// handleRestart runs a restart while still receiving signals. A shutdown
+// signal abandons the restart and is returned so the shutdown can start.
+// The result goes to reply, or is logged for a signal-triggered restart.
+// Zombies are not reaped meanwhile, since that could steal the exit status
+// of the old instance.
+func (m *Manager) handleRestart(reply chan error, sigChan chan os.Signal, signalHandler *SignalHandler) os.Signal {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-		case ActionReapZombies:
-			m.reapZombies()
+	done := make(chan error, 1)
+	go func() {
+		done <- m.runRestart(ctx, signalHandler)
+	}()
 
-		case ActionPassThrough:
-			continue
+	var shutdownSig os.Signal
 
-		case ActionIgnore:
-			continue
+	for {
+		select {
+		case err := <-done:
+			switch {
+			case reply != nil:
+				reply <- err
+			case err != nil:
+				slog.Error("Application restart failed", "error", err)
+			}
+			return shutdownSig
+
+		case other := <-m.restartRequests:
+			other <- ErrRestartInProgress
+
+		case sig := <-sigChan:
+			switch signalHandler.Handle(sig) {
+			case ActionShutdown:
+				if shutdownSig == nil {
+					slog.Info("Shutdown signal received during restart, abandoning restart", "signal", sig.String())
+					shutdownSig = sig
+					cancel()
+				}
+			case ActionCancelDrain:
+				m.shutdownCoord.CancelDrain("signal")
+			}
+		}
+	}
+}
+
+// handleShutdown runs the shutdown while still receiving signals, so repeated
+// shutdown signals reach the coordinator. Zombies are not reaped meanwhile,
+// since that could steal the exit status of the application or a hook.
+func (m *Manager) handleShutdown(sig os.Signal, sigChan chan os.Signal, signalHandler *SignalHandler) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- m.shutdownCoord.InitiateShutdown(context.Background(), sig)
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+
+		case reply := <-m.restartRequests:
+			reply <- ErrShuttingDown
+
+		case sig := <-sigChan:
+			switch signalHandler.Handle(sig) {
+			case ActionShutdown:
+				m.shutdownCoord.HandleRepeatSignal(sig)
+			case ActionCancelDrain:
+				m.shutdownCoord.CancelDrain("signal")
+			}
 		}
 	}
 }
@@ -15,13 +15,17 @@
 package process
 
 import (
+	"context"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
 
 	"github.com/jpasei/zerohalt/pkg/health"
+	"github.com/jpasei/zerohalt/pkg/hooks"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,7 +64,7 @@ func (m *mockConfig) GetSignalConfig() SignalConfig {
 	}
 }
 
-func (m *mockConfig) GetConnectionCheckInterval() interface{} {
+func (m *mockConfig) GetConnectionCheckInterval() time.Duration {
 	return 1 * time.Second
 }
 
@@ -74,11 +78,11 @@ func (m *mockConfig) GetHealthProbeInterval() time.Duration {
 
 type mockShutdownConfig struct{}
 
-func (m *mockShutdownConfig) GetDrainTimeout() interface{} {
+func (m *mockShutdownConfig) GetDrainTimeout() time.Duration {
 	return 60 * time.Second
 }
 
-func (m *mockShutdownConfig) GetShutdownTimeout() interface{} {
+func (m *mockShutdownConfig) GetShutdownTimeout() time.Duration {
 	return 30 * time.Second
 }
 
@@ -110,33 +114,60 @@ func (m *mockHealthServer) GetState() health.HealthState {
 	return m.state
 }
 
-func (m *mockHealthServer) WaitForAppHealthy(timeout time.Duration, interval time.Duration) bool {
+func (m *mockHealthServer) WaitForAppHealthy(ctx context.Context, timeout time.Duration, interval time.Duration) bool {
 	m.waitForAppCalled = true
 	return true
 }
 
-type mockConnectionMonitor struct{}
+type mockConnectionMonitor struct {
+	mu      sync.Mutex
+	process *os.Process
+}
 
 func (m *mockConnectionMonitor) CountActiveConnections() (int, error) {
 	return 0, nil
 }
 
-func (m *mockConnectionMonitor) WaitForZeroConnections(timeout interface{}) error {
+func (m *mockConnectionMonitor) WaitForZeroConnections(ctx context.Context, timeout time.Duration) error {
 	return nil
 }
 
+func (m *mockConnectionMonitor) SetAppProcess(appProcess *os.Process) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.process = appProcess
+}
+
+func (m *mockConnectionMonitor) currentProcess() *os.Process {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.process
+}
+
 type mockShutdownCoordinator struct {
-	process *os.Process
+	process      *os.Process
+	cancelDrains int
+}
+
+func (m *mockShutdownCoordinator) InitiateShutdown(ctx context.Context, sig os.Signal) error {
+	return nil
+}
+
+func (m *mockShutdownCoordinator) HandleRepeatSignal(sig os.Signal) {
 }
 
-func (m *mockShutdownCoordinator) InitiateShutdown(sig os.Signal) error {
+func (m *mockShutdownCoordinator) CancelDrain(source string) error {
+	m.cancelDrains++
 	return nil
 }
 
-func (m *mockShutdownCoordinator) SetAppProcess(appProcess *os.Process) {
+func (m *mockShutdownCoordinator) SetAppProcess(appProcess *os.Process, exit *AppExit) {
 	m.process = appProcess
 }
 
+func (m *mockShutdownCoordinator) SetListenerFiles(files []*os.File) {
+}
+
 func TestNewManager(t *testing.T) {
 	cfg := &mockConfig{}
 	manager := NewManager(cfg)
@@ -178,6 +209,7 @@ func TestManager_Run_HealthServerStart(t *testing.T) {
 	time.Sleep(200 * time.Millisecond)
 
 	assert.True(t, healthServer.started)
+	assert.Equal(t, manager.app.Process, connMonitor.process)
 
 	manager.app.Process.Kill()
 
@@ -204,7 +236,7 @@ func (m *mockHealthServerWithError) GetState() health.HealthState {
 	return m.state
 }
 
-func (m *mockHealthServerWithError) WaitForAppHealthy(timeout time.Duration, interval time.Duration) bool {
+func (m *mockHealthServerWithError) WaitForAppHealthy(ctx context.Context, timeout time.Duration, interval time.Duration) bool {
 	return true
 }
 
@@ -594,7 +626,178 @@ func (m *mockHealthServerThatFailsHealthCheck) GetState() health.HealthState {
 	return m.state
 }
 
-func (m *mockHealthServerThatFailsHealthCheck) WaitForAppHealthy(timeout time.Duration, interval time.Duration) bool {
+func (m *mockHealthServerThatFailsHealthCheck) WaitForAppHealthy(ctx context.Context, timeout time.Duration, interval time.Duration) bool {
 	m.waitForAppCalled = true
 	return false
 }
+
+func TestManager_Run_PreStartHookFailure(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "app-started")
+
+	cfg := &mockConfig{command: []string{"touch", marker}}
+	manager := NewManager(cfg)
+	manager.SetPreStartHooks([]hooks.Hook{
+		{Name: "pre-start-1", Command: []string{"false"}, Timeout: 5 * time.Second},
+	})
+
+	healthServer := &mockHealthServer{}
+	err := manager.Run(healthServer, &mockConnectionMonitor{}, &mockShutdownCoordinator{})
+
+	assert.ErrorContains(t, err, "pre-start hook failed")
+	assert.Equal(t, health.StateStarting, healthServer.GetState())
+
+	_, statErr := os.Stat(marker)
+	assert.True(t, os.IsNotExist(statErr), "the application should not start after a failed hook")
+}
+
+func TestManager_Run_ShutdownSignalDuringPreStartHook(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "app-started")
+
+	cfg := &mockConfig{command: []string{"touch", marker}}
+	manager := NewManager(cfg)
+	manager.SetPreStartHooks([]hooks.Hook{
+		{Name: "pre-start-1", Command: []string{"sleep", "30"}, Timeout: time.Minute},
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Run(&mockHealthServer{}, &mockConnectionMonitor{}, &mockShutdownCoordinator{})
+	}()
+
+	time.Sleep(300 * time.Millisecond)
+
+	currentProc, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	currentProc.Signal(syscall.SIGTERM)
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("a shutdown signal should stop the pre-start hook")
+	}
+
+	_, statErr := os.Stat(marker)
+	assert.True(t, os.IsNotExist(statErr), "the application should not start after shutdown was requested")
+	assert.ErrorIs(t, manager.Restart(), ErrShuttingDown)
+}
+
+// blockingShutdownCoordinator keeps InitiateShutdown running until release
+// is closed and reports repeated signals on repeats.
+type blockingShutdownCoordinator struct {
+	mockShutdownCoordinator
+	release chan struct{}
+	repeats chan os.Signal
+}
+
+func (b *blockingShutdownCoordinator) InitiateShutdown(ctx context.Context, sig os.Signal) error {
+	<-b.release
+	return nil
+}
+
+func (b *blockingShutdownCoordinator) HandleRepeatSignal(sig os.Signal) {
+	b.repeats <- sig
+}
+
+func TestManager_handleSignals_RepeatedShutdownSignal(t *testing.T) {
+	manager := NewManager(&mockConfig{})
+	coordinator := &blockingShutdownCoordinator{
+		release: make(chan struct{}),
+		repeats: make(chan os.Signal, 1),
+	}
+	manager.shutdownCoord = coordinator
+
+	signalHandler := NewSignalHandler(&SignalConfig{ShutdownSignals: []string{"SIGTERM", "SIGINT"}}, nil)
+	sigChan := make(chan os.Signal, 1)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- manager.handleSignals(sigChan, signalHandler)
+	}()
+
+	sigChan <- syscall.SIGTERM
+	sigChan <- syscall.SIGINT
+
+	select {
+	case sig := <-coordinator.repeats:
+		assert.Equal(t, syscall.SIGINT, sig)
+	case <-time.After(time.Second):
+		t.Fatal("a shutdown signal during shutdown should reach the coordinator")
+	}
+
+	reply := make(chan error, 1)
+	manager.restartRequests <- reply
+	assert.ErrorIs(t, <-reply, ErrShuttingDown, "restarts are rejected during shutdown")
+
+	close(coordinator.release)
+
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("handleSignals should return once the shutdown finishes")
+	}
+}
+
+// cancellableShutdownCoordinator blocks the first InitiateShutdown until
+// CancelDrain is called, then reports the shutdown as cancelled.
+type cancellableShutdownCoordinator struct {
+	mockShutdownCoordinator
+	cancelled chan struct{}
+	shutdowns int
+}
+
+func (c *cancellableShutdownCoordinator) InitiateShutdown(ctx context.Context, sig os.Signal) error {
+	c.shutdowns++
+	if c.shutdowns > 1 {
+		return nil
+	}
+
+	<-c.cancelled
+	return ErrShutdownCancelled
+}
+
+func (c *cancellableShutdownCoordinator) CancelDrain(source string) error {
+	close(c.cancelled)
+	return nil
+}
+
+func TestManager_handleSignals_CancelledShutdownKeepsRunning(t *testing.T) {
+	manager := NewManager(&mockConfig{})
+	coordinator := &cancellableShutdownCoordinator{cancelled: make(chan struct{})}
+	manager.shutdownCoord = coordinator
+
+	signalConfig := &SignalConfig{ShutdownSignals: []string{"SIGTERM"}, CancelDrainSignal: "SIGUSR1"}
+	signalHandler := NewSignalHandler(signalConfig, nil)
+	sigChan := make(chan os.Signal, 3)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- manager.handleSignals(sigChan, signalHandler)
+	}()
+
+	sigChan <- syscall.SIGTERM
+	sigChan <- syscall.SIGUSR1
+
+	select {
+	case <-coordinator.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the cancel drain signal should reach the coordinator during shutdown")
+	}
+
+	select {
+	case err := <-result:
+		t.Fatalf("handleSignals returned after a cancelled shutdown: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	sigChan <- syscall.SIGTERM
+
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+		assert.Equal(t, 2, coordinator.shutdowns)
+	case <-time.After(time.Second):
+		t.Fatal("a shutdown signal after a cancelled drain should start a new shutdown")
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/health"
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+var (
+	ErrRestartInProgress = errors.New("application restart already in progress")
+	ErrShuttingDown      = errors.New("process manager is shutting down")
+	ErrRestartUnhealthy  = errors.New("restarted application did not become healthy")
+)
+
+const appPortPollInterval = 100 * time.Millisecond
+
+// RequestGate holds incoming requests while the application is replaced, so
+// clients see a slower response instead of a refused connection.
+type RequestGate interface {
+	Hold()
+	Release()
+	WaitForIdle(ctx context.Context, timeout time.Duration) bool
+}
+
+func (m *Manager) SetRequestGate(gate RequestGate) {
+	m.requestGate = gate
+}
+
+// Restart replaces the running application with a new instance of the same
+// command. A shutdown signal during the restart abandons it, so a restart
+// never overlaps a shutdown.
+func (m *Manager) Restart() error {
+	if m.restarting.Load() {
+		return ErrRestartInProgress
+	}
+
+	reply := make(chan error, 1)
+
+	select {
+	case m.restartRequests <- reply:
+	case <-m.stopped:
+		return ErrShuttingDown
+	}
+
+	return <-reply
+}
+
+// runRestart restarts the application. Cancelling ctx abandons the restart
+// so a shutdown can take over; it then returns ErrShuttingDown.
+func (m *Manager) runRestart(ctx context.Context, signalHandler *SignalHandler) error {
+	m.restarting.Store(true)
+	defer m.restarting.Store(false)
+
+	err := m.restartApp(ctx, signalHandler)
+	if ctx.Err() != nil {
+		slog.Warn("Application restart abandoned for shutdown", "error", err)
+		metrics.AppRestarts.WithLabelValues("cancelled").Inc()
+		return ErrShuttingDown
+	}
+
+	if err != nil {
+		metrics.AppRestarts.WithLabelValues("failed").Inc()
+		m.healthServer.SetState(health.StateUnhealthy)
+		return err
+	}
+
+	metrics.AppRestarts.WithLabelValues("success").Inc()
+	m.healthServer.SetState(health.StateHealthy)
+	return nil
+}
+
+func (m *Manager) restartApp(ctx context.Context, signalHandler *SignalHandler) error {
+	oldPID := m.app.Process.Pid
+	slog.Info("Restarting application", "pid", oldPID)
+
+	shutdownConfig := m.config.GetShutdownConfig()
+
+	if m.requestGate != nil {
+		m.requestGate.Hold()
+		defer m.requestGate.Release()
+
+		drainTimeout := shutdownConfig.GetDrainTimeout()
+		if !m.requestGate.WaitForIdle(ctx, drainTimeout) && ctx.Err() == nil {
+			slog.Warn("In-flight requests still running, restarting anyway", "timeout", drainTimeout)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.stopApp(ctx, shutdownConfig)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := m.startApp(); err != nil {
+		return err
+	}
+	signalHandler.SetAppProcess(m.app.Process)
+
+	startupTimeout := m.config.GetAppStartupTimeout()
+	probeInterval := m.config.GetHealthProbeInterval()
+
+	portReady := m.waitForAppPort(ctx, startupTimeout)
+	healthy := portReady && m.healthServer.WaitForAppHealthy(ctx, startupTimeout, probeInterval)
+	if !healthy {
+		return fmt.Errorf("%w (pid %d)", ErrRestartUnhealthy, m.app.Process.Pid)
+	}
+
+	slog.Info("Application restarted", "old_pid", oldPID, "pid", m.app.Process.Pid)
+	return nil
+}
+
+// stopApp stops the old instance, escalating to SIGKILL after the shutdown
+// timeout. Cancelling ctx stops waiting and leaves the rest to the shutdown.
+func (m *Manager) stopApp(ctx context.Context, shutdownConfig ShutdownConfig) {
+	sig := ParseSignal(shutdownConfig.GetSignalToApp())
+	if sig == nil {
+		sig = syscall.SIGTERM
+	}
+
+	if err := m.app.Process.Signal(sig); err != nil {
+		slog.Warn("Error sending signal to app", "signal", sig.String(), "error", err)
+	}
+
+	shutdownTimeout := shutdownConfig.GetShutdownTimeout()
+	exited := waitForExit(ctx, m.appExit, shutdownTimeout)
+	if exited || ctx.Err() != nil {
+		return
+	}
+
+	slog.Warn("Application did not exit for restart, sending SIGKILL", "pid", m.app.Process.Pid, "timeout", shutdownTimeout)
+	m.app.Process.Signal(syscall.SIGKILL)
+	waitForExit(ctx, m.appExit, shutdownTimeout)
+}
+
+// waitForExit reports whether the instance exited within timeout.
+func waitForExit(ctx context.Context, exit *AppExit, timeout time.Duration) bool {
+	select {
+	case <-exit.Done():
+		return true
+	case <-time.After(timeout):
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (m *Manager) waitForAppPort(ctx context.Context, timeout time.Duration) bool {
+	addr := fmt.Sprintf("localhost:%d", m.config.GetAppPort())
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		conn, err := net.DialTimeout("tcp", addr, appPortPollInterval)
+		if err == nil {
+			conn.Close()
+			return true
+		}
+
+		time.Sleep(appPortPollInterval)
+	}
+
+	slog.Warn("Restarted application is not accepting connections", "addr", addr, "timeout", timeout)
+	return false
+}
@@ -0,0 +1,259 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package process
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRequestGate struct {
+	calls      []string
+	idleResult bool
+}
+
+func (m *mockRequestGate) Hold() {
+	m.calls = append(m.calls, "hold")
+}
+
+func (m *mockRequestGate) Release() {
+	m.calls = append(m.calls, "release")
+}
+
+func (m *mockRequestGate) WaitForIdle(ctx context.Context, timeout time.Duration) bool {
+	m.calls = append(m.calls, "wait_for_idle")
+	return m.idleResult
+}
+
+// listenOnAppPort stands in for the restarted application accepting
+// connections.
+func listenOnAppPort(t *testing.T) uint16 {
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	return uint16(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func startManagerLoop(t *testing.T, manager *Manager, healthServer HealthServer) (*mockConnectionMonitor, *mockShutdownCoordinator, chan os.Signal) {
+	connMonitor := &mockConnectionMonitor{}
+	shutdownCoord := &mockShutdownCoordinator{}
+
+	manager.healthServer = healthServer
+	manager.connMonitor = connMonitor
+	manager.shutdownCoord = shutdownCoord
+
+	err := manager.startApp()
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		manager.app.Process.Kill()
+	})
+
+	signalConfig := SignalConfig{
+		ShutdownSignals: []string{"SIGTERM"},
+		RestartSignal:   "SIGUSR2",
+	}
+	signalHandler := NewSignalHandler(&signalConfig, manager.app.Process)
+
+	sigChan := make(chan os.Signal, 10)
+	go manager.handleSignals(sigChan, signalHandler)
+
+	return connMonitor, shutdownCoord, sigChan
+}
+
+func TestManager_Restart(t *testing.T) {
+	cfg := &mockConfig{
+		command: []string{"sleep", "10"},
+		port:    listenOnAppPort(t),
+	}
+	manager := NewManager(cfg)
+	gate := &mockRequestGate{idleResult: true}
+	manager.SetRequestGate(gate)
+
+	healthServer := &mockHealthServer{}
+	connMonitor, shutdownCoord, _ := startManagerLoop(t, manager, healthServer)
+	oldProcess := manager.app.Process
+
+	err := manager.Restart()
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, oldProcess.Pid, manager.app.Process.Pid)
+	assert.Equal(t, manager.app.Process, connMonitor.process)
+	assert.Equal(t, manager.app.Process, shutdownCoord.process)
+	assert.Equal(t, []string{"hold", "wait_for_idle", "release"}, gate.calls)
+	assert.True(t, healthServer.waitForAppCalled)
+	assert.Equal(t, health.StateHealthy, healthServer.state)
+
+	assert.Error(t, oldProcess.Signal(syscall.Signal(0)), "old instance should have exited")
+}
+
+func TestManager_Restart_BySignal(t *testing.T) {
+	cfg := &mockConfig{
+		command: []string{"sleep", "10"},
+		port:    listenOnAppPort(t),
+	}
+	manager := NewManager(cfg)
+
+	connMonitor, _, sigChan := startManagerLoop(t, manager, &mockHealthServer{})
+	oldPID := manager.app.Process.Pid
+
+	sigChan <- syscall.SIGUSR2
+
+	assert.Eventually(t, func() bool {
+		process := connMonitor.currentProcess()
+		return process != nil && process.Pid != oldPID
+	}, 3*time.Second, 20*time.Millisecond)
+}
+
+func TestManager_Restart_Unhealthy(t *testing.T) {
+	cfg := &mockConfig{
+		command: []string{"sleep", "10"},
+		port:    listenOnAppPort(t),
+	}
+	manager := NewManager(cfg)
+	gate := &mockRequestGate{idleResult: false}
+	manager.SetRequestGate(gate)
+
+	healthServer := &mockHealthServerThatFailsHealthCheck{}
+	startManagerLoop(t, manager, healthServer)
+
+	err := manager.Restart()
+	assert.ErrorIs(t, err, ErrRestartUnhealthy)
+	assert.Equal(t, health.StateUnhealthy, healthServer.state)
+	assert.Equal(t, []string{"hold", "wait_for_idle", "release"}, gate.calls, "held requests are released even when the restart fails")
+}
+
+// stuckRequestGate never goes idle, keeping a restart waiting until its
+// context is cancelled.
+type stuckRequestGate struct {
+	mockRequestGate
+	waiting chan struct{}
+}
+
+func (s *stuckRequestGate) WaitForIdle(ctx context.Context, timeout time.Duration) bool {
+	close(s.waiting)
+	<-ctx.Done()
+	return false
+}
+
+func TestManager_Restart_AbandonedByShutdownSignal(t *testing.T) {
+	cfg := &mockConfig{
+		command: []string{"sleep", "10"},
+		port:    listenOnAppPort(t),
+	}
+	manager := NewManager(cfg)
+	gate := &stuckRequestGate{waiting: make(chan struct{})}
+	manager.SetRequestGate(gate)
+
+	manager.healthServer = &mockHealthServer{}
+	manager.connMonitor = &mockConnectionMonitor{}
+	manager.shutdownCoord = &mockShutdownCoordinator{}
+	assert.NoError(t, manager.startApp())
+	t.Cleanup(func() { manager.app.Process.Kill() })
+	oldProcess := manager.app.Process
+
+	signalHandler := NewSignalHandler(&SignalConfig{ShutdownSignals: []string{"SIGTERM"}}, oldProcess)
+	sigChan := make(chan os.Signal, 1)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- manager.handleSignals(sigChan, signalHandler)
+	}()
+
+	restarted := make(chan error, 1)
+	go func() {
+		restarted <- manager.Restart()
+	}()
+
+	<-gate.waiting
+	sigChan <- syscall.SIGTERM
+
+	select {
+	case err := <-restarted:
+		assert.ErrorIs(t, err, ErrShuttingDown)
+	case <-time.After(time.Second):
+		t.Fatal("a shutdown signal should abandon the restart")
+	}
+
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("handleSignals should shut down after abandoning the restart")
+	}
+
+	assert.Equal(t, oldProcess, manager.app.Process, "the restart must not replace the application")
+	assert.NoError(t, oldProcess.Signal(syscall.Signal(0)), "the shutdown, not the restart, stops the application")
+}
+
+func TestManager_Restart_InProgress(t *testing.T) {
+	manager := NewManager(&mockConfig{})
+	manager.restarting.Store(true)
+
+	err := manager.Restart()
+	assert.ErrorIs(t, err, ErrRestartInProgress)
+}
+
+func TestManager_Restart_AfterShutdown(t *testing.T) {
+	manager := NewManager(&mockConfig{})
+	close(manager.stopped)
+
+	err := manager.Restart()
+	assert.ErrorIs(t, err, ErrShuttingDown)
+}
+
+func TestManager_waitForAppPort_Timeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	manager := NewManager(&mockConfig{port: port})
+
+	ready := manager.waitForAppPort(context.Background(), 300*time.Millisecond)
+	assert.False(t, ready)
+}
+
+func TestWaitForExit_Timeout(t *testing.T) {
+	cmd := exec.Command("sleep", "5")
+	assert.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	exited := waitForExit(context.Background(), WatchExit(cmd.Process), 50*time.Millisecond)
+	assert.False(t, exited)
+}
+
+func TestWaitForExit_SharedAfterKill(t *testing.T) {
+	cmd := exec.Command("sleep", "5")
+	assert.NoError(t, cmd.Start())
+
+	exit := WatchExit(cmd.Process)
+	assert.False(t, waitForExit(context.Background(), exit, 50*time.Millisecond))
+
+	cmd.Process.Kill()
+	assert.True(t, waitForExit(context.Background(), exit, 5*time.Second))
+
+	_, err := exit.Result()
+	assert.NoError(t, err)
+}
@@ -18,11 +18,16 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
 	"github.com/jpasei/zerohalt/pkg/metrics"
 )
 
+// signalBufferSize leaves room for bursts of SIGCHLD, so signal.Notify never
+// drops a shutdown signal while the manager is busy.
+const signalBufferSize = 16
+
 type SignalAction int
 
 const (
@@ -30,17 +35,25 @@ const (
 	ActionPassThrough
 	ActionShutdown
 	ActionReapZombies
+	ActionRestart
+	ActionCancelDrain
 )
 
 type SignalConfig struct {
 	PassThroughSignals []string
 	ShutdownSignals    []string
+	RestartSignal      string
+	CancelDrainSignal  string
 }
 
 type SignalHandler struct {
 	passThroughSignals map[os.Signal]bool
 	shutdownSignals    map[os.Signal]bool
-	appProcess         *os.Process
+	restartSignal      os.Signal
+	cancelDrainSignal  os.Signal
+
+	mu         sync.Mutex
+	appProcess *os.Process
 }
 
 func NewSignalHandler(config *SignalConfig, appProcess *os.Process) *SignalHandler {
@@ -62,11 +75,21 @@ func NewSignalHandler(config *SignalConfig, appProcess *os.Process) *SignalHandl
 		}
 	}
 
+	h.restartSignal = ParseSignal(config.RestartSignal)
+	h.cancelDrainSignal = ParseSignal(config.CancelDrainSignal)
+
 	return h
 }
 
+// SetAppProcess points signal forwarding at a restarted application.
+func (h *SignalHandler) SetAppProcess(appProcess *os.Process) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.appProcess = appProcess
+}
+
 func (h *SignalHandler) Setup() chan os.Signal {
-	shutdownChan := make(chan os.Signal, 1)
+	shutdownChan := make(chan os.Signal, signalBufferSize)
 
 	allSignals := make([]os.Signal, 0)
 
@@ -78,6 +101,14 @@ func (h *SignalHandler) Setup() chan os.Signal {
 		allSignals = append(allSignals, sig)
 	}
 
+	if h.restartSignal != nil {
+		allSignals = append(allSignals, h.restartSignal)
+	}
+
+	if h.cancelDrainSignal != nil {
+		allSignals = append(allSignals, h.cancelDrainSignal)
+	}
+
 	allSignals = append(allSignals, syscall.SIGCHLD)
 
 	signal.Notify(shutdownChan, allSignals...)
@@ -92,6 +123,12 @@ func (h *SignalHandler) Handle(sig os.Signal) SignalAction {
 	case h.shutdownSignals[sig]:
 		return ActionShutdown
 
+	case h.restartSignal != nil && sig == h.restartSignal:
+		return ActionRestart
+
+	case h.cancelDrainSignal != nil && sig == h.cancelDrainSignal:
+		return ActionCancelDrain
+
 	case h.passThroughSignals[sig]:
 		h.forwardSignalToApp(sig)
 		return ActionPassThrough
@@ -106,7 +143,16 @@ func (h *SignalHandler) Handle(sig os.Signal) SignalAction {
 }
 
 func (h *SignalHandler) forwardSignalToApp(sig os.Signal) {
-	err := h.appProcess.Signal(sig)
+	h.mu.Lock()
+	appProcess := h.appProcess
+	h.mu.Unlock()
+
+	if appProcess == nil {
+		slog.Debug("Application not started, dropping signal", "signal", sig.String())
+		return
+	}
+
+	err := appProcess.Signal(sig)
 
 	if err != nil {
 		slog.Error("Failed to forward signal to app", "signal", sig.String(), "error", err)
@@ -114,7 +160,7 @@ func (h *SignalHandler) forwardSignalToApp(sig os.Signal) {
 	}
 
 	metrics.SignalsForwarded.WithLabelValues(sig.String()).Inc()
-	slog.Info("Forwarded signal to application", "signal", sig.String(), "pid", h.appProcess.Pid)
+	slog.Info("Forwarded signal to application", "signal", sig.String(), "pid", appProcess.Pid)
 }
 
 var signalMap = map[string]os.Signal{
@@ -32,6 +32,8 @@ func TestSignalAction_Constants(t *testing.T) {
 		{"ActionPassThrough", ActionPassThrough, 1},
 		{"ActionShutdown", ActionShutdown, 2},
 		{"ActionReapZombies", ActionReapZombies, 3},
+		{"ActionRestart", ActionRestart, 4},
+		{"ActionCancelDrain", ActionCancelDrain, 5},
 	}
 
 	for _, tt := range tests {
@@ -155,3 +157,36 @@ func TestSignalHandler_Handle_PassThrough_Error(t *testing.T) {
 
 	assert.Equal(t, ActionPassThrough, action)
 }
+
+func TestSignalHandler_Handle_Restart(t *testing.T) {
+	config := &SignalConfig{
+		PassThroughSignals: []string{"SIGHUP"},
+		RestartSignal:      "SIGUSR2",
+	}
+
+	handler := NewSignalHandler(config, &os.Process{Pid: 123})
+	action := handler.Handle(syscall.SIGUSR2)
+
+	assert.Equal(t, ActionRestart, action)
+}
+
+func TestSignalHandler_Handle_CancelDrain(t *testing.T) {
+	config := &SignalConfig{
+		ShutdownSignals:   []string{"SIGTERM"},
+		CancelDrainSignal: "SIGUSR1",
+	}
+
+	handler := NewSignalHandler(config, &os.Process{Pid: 123})
+	action := handler.Handle(syscall.SIGUSR1)
+
+	assert.Equal(t, ActionCancelDrain, action)
+}
+
+func TestSignalHandler_SetAppProcess(t *testing.T) {
+	handler := NewSignalHandler(&SignalConfig{}, &os.Process{Pid: 123})
+
+	replacement := &os.Process{Pid: 456}
+	handler.SetAppProcess(replacement)
+
+	assert.Equal(t, replacement, handler.appProcess)
+}
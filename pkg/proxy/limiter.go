@@ -0,0 +1,179 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+var (
+	ErrQueueFull    = errors.New("request queue is full")
+	ErrQueueTimeout = errors.New("timed out waiting in request queue")
+)
+
+// aimdDecreaseFactor shrinks the adaptive limit whenever a response is slower
+// than the latency target.
+const aimdDecreaseFactor = 0.9
+
+// Limiter caps the number of requests forwarded to the application at once.
+// Requests over the limit wait in a bounded FIFO queue.
+type Limiter struct {
+	mu           sync.Mutex
+	limit        float64
+	maxLimit     float64
+	minLimit     float64
+	inUse        int
+	queueSize    int
+	queueTimeout time.Duration
+	waiters      []chan struct{}
+
+	adaptive      bool
+	latencyTarget time.Duration
+}
+
+func NewLimiter(maxConcurrency int, queueSize int, queueTimeout time.Duration) *Limiter {
+	metrics.ProxyConcurrencyLimit.Set(float64(maxConcurrency))
+	metrics.ProxyQueueDepth.Set(0)
+
+	return &Limiter{
+		limit:        float64(maxConcurrency),
+		maxLimit:     float64(maxConcurrency),
+		minLimit:     float64(maxConcurrency),
+		queueSize:    queueSize,
+		queueTimeout: queueTimeout,
+	}
+}
+
+// SetAdaptive lets the limit move between minConcurrency and the configured
+// maximum using AIMD: it grows by one for every limit's worth of responses
+// faster than latencyTarget and shrinks by 10% for each slower response.
+func (l *Limiter) SetAdaptive(minConcurrency int, latencyTarget time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.adaptive = true
+	l.minLimit = float64(minConcurrency)
+	l.latencyTarget = latencyTarget
+}
+
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.capacity()
+}
+
+// Acquire takes a slot, waiting in the queue if none is free.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	l.mu.Lock()
+
+	hasFreeSlot := l.inUse < l.capacity() && len(l.waiters) == 0
+	if hasFreeSlot {
+		l.inUse++
+		l.mu.Unlock()
+		return nil
+	}
+
+	if len(l.waiters) >= l.queueSize {
+		l.mu.Unlock()
+		return ErrQueueFull
+	}
+
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	metrics.ProxyQueueDepth.Set(float64(len(l.waiters)))
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-ready:
+		return nil
+	case <-timer.C:
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The slot may have been handed over while the timer fired; the caller
+	// then owns it and must release it as usual.
+	if !l.removeWaiter(ready) {
+		return nil
+	}
+
+	metrics.ProxyQueueDepth.Set(float64(len(l.waiters)))
+	return err
+}
+
+// Release frees a slot and feeds the response latency into the adaptive
+// limit.
+func (l *Limiter) Release(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inUse--
+	l.adjust(latency)
+	l.dispatch()
+}
+
+func (l *Limiter) capacity() int {
+	return int(l.limit)
+}
+
+func (l *Limiter) adjust(latency time.Duration) {
+	if !l.adaptive {
+		return
+	}
+
+	isSlow := latency > l.latencyTarget
+	if isSlow {
+		l.limit = max(l.minLimit, l.limit*aimdDecreaseFactor)
+	} else {
+		l.limit = min(l.maxLimit, l.limit+1/l.limit)
+	}
+
+	metrics.ProxyConcurrencyLimit.Set(float64(l.capacity()))
+}
+
+func (l *Limiter) dispatch() {
+	for len(l.waiters) > 0 && l.inUse < l.capacity() {
+		next := l.waiters[0]
+		l.waiters = l.waiters[1:]
+		l.inUse++
+		close(next)
+	}
+
+	metrics.ProxyQueueDepth.Set(float64(len(l.waiters)))
+}
+
+func (l *Limiter) removeWaiter(ready chan struct{}) bool {
+	for i, waiter := range l.waiters {
+		if waiter == ready {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
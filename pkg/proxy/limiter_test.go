@@ -0,0 +1,121 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_AcquireWithinLimit(t *testing.T) {
+	l := NewLimiter(2, 0, time.Second)
+
+	assert.NoError(t, l.Acquire(context.Background()))
+	assert.NoError(t, l.Acquire(context.Background()))
+	assert.ErrorIs(t, l.Acquire(context.Background()), ErrQueueFull)
+
+	l.Release(0)
+	assert.NoError(t, l.Acquire(context.Background()))
+}
+
+func TestLimiter_QueueHandsOverSlot(t *testing.T) {
+	l := NewLimiter(1, 1, time.Second)
+	assert.NoError(t, l.Acquire(context.Background()))
+
+	acquired := make(chan error, 1)
+	go func() {
+		acquired <- l.Acquire(context.Background())
+	}()
+
+	assert.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return len(l.waiters) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.ErrorIs(t, l.Acquire(context.Background()), ErrQueueFull)
+
+	l.Release(0)
+	assert.NoError(t, <-acquired)
+	assert.Equal(t, 1, l.inUse)
+}
+
+func TestLimiter_QueueTimeout(t *testing.T) {
+	l := NewLimiter(1, 5, 20*time.Millisecond)
+	assert.NoError(t, l.Acquire(context.Background()))
+
+	err := l.Acquire(context.Background())
+
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+	assert.Empty(t, l.waiters)
+}
+
+func TestLimiter_ContextCancelled(t *testing.T) {
+	l := NewLimiter(1, 5, time.Second)
+	assert.NoError(t, l.Acquire(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := l.Acquire(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, l.waiters)
+}
+
+func TestLimiter_AdaptiveDecreasesOnSlowResponses(t *testing.T) {
+	l := NewLimiter(10, 0, time.Second)
+	l.SetAdaptive(2, 100*time.Millisecond)
+
+	for i := 0; i < 50; i++ {
+		assert.NoError(t, l.Acquire(context.Background()))
+		l.Release(time.Second)
+	}
+
+	assert.Equal(t, 2, l.Limit(), "limit never drops below the minimum")
+}
+
+func TestLimiter_AdaptiveIncreasesOnFastResponses(t *testing.T) {
+	l := NewLimiter(10, 0, time.Second)
+	l.SetAdaptive(2, 100*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, l.Acquire(context.Background()))
+		l.Release(time.Second)
+	}
+	lowered := l.Limit()
+	assert.Less(t, lowered, 10)
+
+	for i := 0; i < 200; i++ {
+		assert.NoError(t, l.Acquire(context.Background()))
+		l.Release(time.Millisecond)
+	}
+
+	assert.Equal(t, 10, l.Limit(), "limit never exceeds the maximum")
+}
+
+func TestLimiter_StaticLimitIgnoresLatency(t *testing.T) {
+	l := NewLimiter(4, 0, time.Second)
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, l.Acquire(context.Background()))
+		l.Release(time.Minute)
+	}
+
+	assert.Equal(t, 4, l.Limit())
+}
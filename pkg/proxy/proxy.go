@@ -0,0 +1,323 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+// Proxy is an HTTP reverse proxy in front of the application. Unlike socket
+// counting it can tell an in-flight request from an idle keep-alive
+// connection, so a drain finishes as soon as the last response is written.
+type Proxy struct {
+	listenPort uint16
+	targetPort uint16
+	server     *http.Server
+	transport  *http.Transport
+	handler    *httputil.ReverseProxy
+	inFlight   atomic.Int64
+	draining   atomic.Bool
+
+	holdQueueSize int
+	holdTimeout   time.Duration
+	limiter       *Limiter
+
+	mu        sync.Mutex
+	held      chan struct{}
+	queued    atomic.Int64
+	forwarded atomic.Int64
+}
+
+const (
+	defaultHoldQueueSize = 100
+	defaultHoldTimeout   = 30 * time.Second
+	idlePollInterval     = 50 * time.Millisecond
+)
+
+func NewProxy(listenPort uint16, targetPort uint16) *Proxy {
+	target := &url.URL{
+		Scheme: "http",
+		Host:   fmt.Sprintf("localhost:%d", targetPort),
+	}
+
+	p := &Proxy{
+		listenPort:    listenPort,
+		targetPort:    targetPort,
+		transport:     http.DefaultTransport.(*http.Transport).Clone(),
+		holdQueueSize: defaultHoldQueueSize,
+		holdTimeout:   defaultHoldTimeout,
+	}
+
+	p.handler = httputil.NewSingleHostReverseProxy(target)
+	p.handler.Transport = p.transport
+	p.handler.ErrorHandler = p.errorHandler
+
+	p.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", listenPort),
+		Handler: p,
+	}
+
+	return p
+}
+
+// SetHoldLimits bounds how many requests are held during a restart and how
+// long each of them waits before it is rejected.
+func (p *Proxy) SetHoldLimits(queueSize int, timeout time.Duration) {
+	p.holdQueueSize = queueSize
+	p.holdTimeout = timeout
+}
+
+// SetLimiter caps concurrent requests to the application. Requests over the
+// limit are queued by the limiter and shed with a 503 when it is full.
+func (p *Proxy) SetLimiter(limiter *Limiter) {
+	p.limiter = limiter
+}
+
+// Start binds the public port before returning so that a port conflict is
+// reported to the caller instead of only being logged.
+func (p *Proxy) Start() error {
+	listener, err := net.Listen("tcp", p.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on proxy port %d: %w", p.listenPort, err)
+	}
+
+	go func() {
+		if err := p.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("Proxy server error", "error", err)
+		}
+	}()
+
+	slog.Info("Proxy started", "listen_port", p.listenPort, "target_port", p.targetPort)
+	return nil
+}
+
+func (p *Proxy) Shutdown(ctx context.Context) error {
+	return p.server.Shutdown(ctx)
+}
+
+// SetDraining asks clients to stop reusing their connections. Responses
+// carry Connection: close from now on and idle keep-alive connections, both
+// to clients and to the application, are closed.
+func (p *Proxy) SetDraining() {
+	alreadyDraining := p.draining.Swap(true)
+	if alreadyDraining {
+		return
+	}
+
+	p.server.SetKeepAlivesEnabled(false)
+	p.transport.CloseIdleConnections()
+
+	slog.Info("Proxy draining, closing idle connections", "in_flight", p.InFlight())
+}
+
+// ResumeFromDraining undoes SetDraining after a cancelled drain, so clients
+// may keep their connections open again.
+func (p *Proxy) ResumeFromDraining() {
+	wasDraining := p.draining.Swap(false)
+	if !wasDraining {
+		return
+	}
+
+	p.server.SetKeepAlivesEnabled(true)
+	slog.Info("Proxy back in service, keep-alives enabled")
+}
+
+// InFlight counts requests that clients are still waiting on, including
+// requests held during a restart.
+func (p *Proxy) InFlight() int {
+	return int(p.inFlight.Load())
+}
+
+// Hold queues new requests instead of forwarding them, until Release.
+func (p *Proxy) Hold() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.held == nil {
+		p.held = make(chan struct{})
+		slog.Info("Proxy holding new requests")
+	}
+}
+
+// Release forwards the held requests to the application.
+func (p *Proxy) Release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.held == nil {
+		return
+	}
+
+	// Keep-alive connections to the previous instance are dead by now.
+	p.transport.CloseIdleConnections()
+
+	close(p.held)
+	p.held = nil
+	slog.Info("Proxy releasing held requests", "held", p.queued.Load())
+}
+
+// WaitForIdle waits until no request is being forwarded to the application,
+// the timeout expires, or ctx is cancelled. Held requests are not counted.
+func (p *Proxy) WaitForIdle(ctx context.Context, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(idlePollInterval)
+	defer ticker.Stop()
+
+	for p.forwarded.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+
+	return true
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.inFlight.Add(1)
+	metrics.ProxyInFlightRequests.Inc()
+	metrics.ProxyRequests.Inc()
+
+	defer func() {
+		p.inFlight.Add(-1)
+		metrics.ProxyInFlightRequests.Dec()
+	}()
+
+	admitted := p.waitForAdmission(w, r)
+	if !admitted {
+		return
+	}
+	defer p.forwarded.Add(-1)
+
+	if p.limiter != nil {
+		if err := p.limiter.Acquire(r.Context()); err != nil {
+			p.shed(w, err)
+			return
+		}
+
+		start := time.Now()
+		defer func() {
+			p.limiter.Release(time.Since(start))
+		}()
+	}
+
+	if p.draining.Load() {
+		w.Header().Set("Connection", "close")
+	}
+
+	p.handler.ServeHTTP(w, r)
+}
+
+// waitForAdmission counts the request as forwarded once no hold is active.
+// The check and the count happen under the same lock as Hold, so a restart
+// never misses a request that is about to reach the old instance.
+func (p *Proxy) waitForAdmission(w http.ResponseWriter, r *http.Request) bool {
+	for {
+		p.mu.Lock()
+		held := p.held
+		if held == nil {
+			p.forwarded.Add(1)
+		}
+		p.mu.Unlock()
+
+		if held == nil {
+			return true
+		}
+
+		if !p.waitForRelease(w, r, held) {
+			return false
+		}
+	}
+}
+
+func (p *Proxy) waitForRelease(w http.ResponseWriter, r *http.Request, held chan struct{}) bool {
+	queued := p.queued.Add(1)
+	metrics.ProxyHeldRequests.Inc()
+
+	defer func() {
+		p.queued.Add(-1)
+		metrics.ProxyHeldRequests.Dec()
+	}()
+
+	queueFull := queued > int64(p.holdQueueSize)
+	if queueFull {
+		p.rejectHeld(w, "queue_full")
+		return false
+	}
+
+	timer := time.NewTimer(p.holdTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-held:
+		return true
+	case <-timer.C:
+		p.rejectHeld(w, "timeout")
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+func (p *Proxy) rejectHeld(w http.ResponseWriter, reason string) {
+	metrics.ProxyHoldRejected.WithLabelValues(reason).Inc()
+	slog.Warn("Rejected held request", "reason", reason)
+
+	writeUnavailable(w)
+}
+
+func (p *Proxy) shed(w http.ResponseWriter, err error) {
+	var reason string
+
+	switch {
+	case errors.Is(err, ErrQueueFull):
+		reason = "queue_full"
+	case errors.Is(err, ErrQueueTimeout):
+		reason = "timeout"
+	default:
+		// The client went away while queued.
+		return
+	}
+
+	metrics.ProxyShed.WithLabelValues(reason).Inc()
+	slog.Debug("Shed request", "reason", reason)
+
+	writeUnavailable(w)
+}
+
+func writeUnavailable(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
+
+func (p *Proxy) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	slog.Warn("Proxy request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+	w.WriteHeader(http.StatusBadGateway)
+}
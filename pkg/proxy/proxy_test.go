@@ -0,0 +1,324 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startBackend(t *testing.T, handler http.HandlerFunc) uint16 {
+	backend := httptest.NewServer(handler)
+	t.Cleanup(backend.Close)
+
+	_, portStr, err := net.SplitHostPort(backend.Listener.Addr().String())
+	assert.NoError(t, err)
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	assert.NoError(t, err)
+
+	return uint16(port)
+}
+
+func TestNewProxy(t *testing.T) {
+	p := NewProxy(9000, 8080)
+
+	assert.NotNil(t, p)
+	assert.Equal(t, uint16(9000), p.listenPort)
+	assert.Equal(t, uint16(8080), p.targetPort)
+	assert.Equal(t, ":9000", p.server.Addr)
+	assert.Equal(t, 0, p.InFlight())
+}
+
+func TestProxy_ServeHTTP_Forwards(t *testing.T) {
+	backendPort := startBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Backend", "app")
+		w.Write([]byte("hello from " + r.URL.Path))
+	})
+	p := NewProxy(getAvailablePort(), backendPort)
+
+	req := httptest.NewRequest("GET", "/greet", nil)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "app", w.Header().Get("X-Backend"))
+	assert.Equal(t, "hello from /greet", w.Body.String())
+	assert.Empty(t, w.Header().Get("Connection"))
+}
+
+func TestProxy_ServeHTTP_CountsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	backendPort := startBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	p := NewProxy(getAvailablePort(), backendPort)
+
+	done := make(chan struct{})
+	go func() {
+		p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+		close(done)
+	}()
+
+	<-started
+	assert.Equal(t, 1, p.InFlight())
+
+	close(release)
+	<-done
+	assert.Equal(t, 0, p.InFlight())
+}
+
+func TestProxy_ServeHTTP_DrainingClosesConnection(t *testing.T) {
+	backendPort := startBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	p := NewProxy(getAvailablePort(), backendPort)
+
+	p.SetDraining()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+}
+
+func TestProxy_ServeHTTP_BackendUnavailable(t *testing.T) {
+	p := NewProxy(getAvailablePort(), getAvailablePort())
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Equal(t, 0, p.InFlight())
+}
+
+func TestProxy_SetDraining_Idempotent(t *testing.T) {
+	p := NewProxy(getAvailablePort(), getAvailablePort())
+
+	p.SetDraining()
+	p.SetDraining()
+
+	assert.True(t, p.draining.Load())
+}
+
+func TestProxy_ResumeFromDraining(t *testing.T) {
+	backendPort := startBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	p := NewProxy(getAvailablePort(), backendPort)
+
+	p.SetDraining()
+	p.ResumeFromDraining()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, req)
+
+	assert.False(t, p.draining.Load())
+	assert.Empty(t, w.Header().Get("Connection"))
+}
+
+func TestProxy_StartAndShutdown(t *testing.T) {
+	backendPort := startBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	listenPort := getAvailablePort()
+	p := NewProxy(listenPort, backendPort)
+
+	err := p.Start()
+	assert.NoError(t, err)
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/", listenPort))
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(body))
+	assert.False(t, resp.Close)
+
+	p.SetDraining()
+
+	resp, err = http.Get(fmt.Sprintf("http://localhost:%d/", listenPort))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.True(t, resp.Close, "draining responses should ask the client to close the connection")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	assert.NoError(t, p.Shutdown(ctx))
+}
+
+func TestProxy_Start_PortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+	p := NewProxy(port, getAvailablePort())
+
+	err = p.Start()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to listen on proxy port")
+}
+
+func TestProxy_Hold_ReleasesQueuedRequests(t *testing.T) {
+	backendPort := startBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("replayed"))
+	})
+	p := NewProxy(getAvailablePort(), backendPort)
+
+	p.Hold()
+
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		p.ServeHTTP(w, httptest.NewRequest("POST", "/orders", nil))
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return p.queued.Load() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, p.InFlight(), "held requests count as in flight")
+	assert.True(t, p.WaitForIdle(context.Background(), 10*time.Millisecond), "held requests do not keep the application busy")
+
+	p.Release()
+	<-done
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "replayed", w.Body.String())
+	assert.Equal(t, 0, p.InFlight())
+}
+
+func TestProxy_Hold_QueueFull(t *testing.T) {
+	p := NewProxy(getAvailablePort(), getAvailablePort())
+	p.SetHoldLimits(1, time.Second)
+	p.Hold()
+	defer p.Release()
+
+	go p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Eventually(t, func() bool { return p.queued.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+}
+
+func TestProxy_Hold_Timeout(t *testing.T) {
+	p := NewProxy(getAvailablePort(), getAvailablePort())
+	p.SetHoldLimits(10, 20*time.Millisecond)
+	p.Hold()
+	defer p.Release()
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, 0, p.InFlight())
+}
+
+func TestProxy_Hold_ClientGone(t *testing.T) {
+	p := NewProxy(getAvailablePort(), getAvailablePort())
+	p.Hold()
+	defer p.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+
+	assert.Equal(t, 0, p.InFlight())
+}
+
+func TestProxy_WaitForIdle(t *testing.T) {
+	release := make(chan struct{})
+	backendPort := startBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	p := NewProxy(getAvailablePort(), backendPort)
+
+	go p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Eventually(t, func() bool { return p.forwarded.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	assert.False(t, p.WaitForIdle(context.Background(), 60*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, p.WaitForIdle(ctx, time.Second))
+
+	close(release)
+	assert.True(t, p.WaitForIdle(context.Background(), time.Second))
+}
+
+func TestProxy_Release_WithoutHold(t *testing.T) {
+	p := NewProxy(getAvailablePort(), getAvailablePort())
+
+	p.Release()
+
+	assert.Nil(t, p.held)
+}
+
+func TestProxy_Limiter_ShedsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	backendPort := startBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	p := NewProxy(getAvailablePort(), backendPort)
+	p.SetLimiter(NewLimiter(1, 0, time.Second))
+
+	done := make(chan struct{})
+	go func() {
+		p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return p.forwarded.Load() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		p.limiter.mu.Lock()
+		defer p.limiter.mu.Unlock()
+		return p.limiter.inUse == 1
+	}, time.Second, 10*time.Millisecond)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	close(release)
+	<-done
+}
+
+func TestProxy_Shed_ClientGone(t *testing.T) {
+	p := NewProxy(getAvailablePort(), getAvailablePort())
+
+	w := httptest.NewRecorder()
+	p.shed(w, context.Canceled)
+
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
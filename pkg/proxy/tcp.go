@@ -0,0 +1,308 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+type CloseMode string
+
+const (
+	CloseModeHalfClose CloseMode = "half-close"
+	CloseModeReset     CloseMode = "reset"
+)
+
+const (
+	defaultDrainGrace       = 30 * time.Second
+	defaultHalfCloseTimeout = 5 * time.Second
+	upstreamDialTimeout     = 5 * time.Second
+)
+
+// TCPProxy forwards raw TCP connections to the application. It knows the
+// exact number of open connections, and draining closes the public listener
+// while existing connections keep running for a grace period.
+type TCPProxy struct {
+	listenPort uint16
+	targetPort uint16
+	drainGrace time.Duration
+	closeMode  CloseMode
+	// halfCloseTimeout is how long a half-closed connection may stay open
+	// before it is reset.
+	halfCloseTimeout time.Duration
+
+	mu         sync.Mutex
+	listener   net.Listener
+	sessions   map[*tcpSession]struct{}
+	draining   bool
+	drainTimer *time.Timer
+}
+
+type tcpSession struct {
+	client   net.Conn
+	upstream net.Conn
+	start    time.Time
+}
+
+func NewTCPProxy(listenPort uint16, targetPort uint16) *TCPProxy {
+	return &TCPProxy{
+		listenPort:       listenPort,
+		targetPort:       targetPort,
+		drainGrace:       defaultDrainGrace,
+		closeMode:        CloseModeHalfClose,
+		halfCloseTimeout: defaultHalfCloseTimeout,
+		sessions:         make(map[*tcpSession]struct{}),
+	}
+}
+
+// SetDrainGrace sets how long connections may stay open once draining starts
+// and how they are closed afterwards.
+func (p *TCPProxy) SetDrainGrace(grace time.Duration, mode CloseMode) {
+	p.drainGrace = grace
+	p.closeMode = mode
+}
+
+func (p *TCPProxy) Start() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", p.listenPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen on proxy port %d: %w", p.listenPort, err)
+	}
+
+	p.mu.Lock()
+	p.listener = listener
+	p.mu.Unlock()
+
+	go p.acceptLoop(listener)
+
+	slog.Info("TCP proxy started", "listen_port", p.listenPort, "target_port", p.targetPort)
+	return nil
+}
+
+// InFlight reports the number of open proxied connections.
+func (p *TCPProxy) InFlight() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.sessions)
+}
+
+// SetDraining stops accepting new connections and schedules the remaining
+// ones to be closed once the grace period has passed.
+func (p *TCPProxy) SetDraining() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.draining {
+		return
+	}
+	p.draining = true
+
+	if p.listener != nil {
+		p.listener.Close()
+	}
+
+	slog.Info("TCP proxy draining, stopped accepting connections", "open_connections", len(p.sessions), "grace", p.drainGrace, "close_mode", p.closeMode)
+	p.drainTimer = time.AfterFunc(p.drainGrace, p.closeRemaining)
+}
+
+// Close stops the listener and resets every open connection.
+func (p *TCPProxy) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.draining = true
+	if p.drainTimer != nil {
+		p.drainTimer.Stop()
+	}
+
+	for session := range p.sessions {
+		resetConn(session.client)
+		resetConn(session.upstream)
+	}
+
+	if p.listener == nil {
+		return nil
+	}
+	return p.listener.Close()
+}
+
+// Shutdown stops accepting connections and waits for the open ones to
+// finish. Connections still open when ctx ends are reset.
+func (p *TCPProxy) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.draining = true
+	if p.listener != nil {
+		p.listener.Close()
+	}
+	p.mu.Unlock()
+
+	ticker := time.NewTicker(idlePollInterval)
+	defer ticker.Stop()
+
+	for p.InFlight() > 0 {
+		select {
+		case <-ctx.Done():
+			p.Close()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+func (p *TCPProxy) acceptLoop(listener net.Listener) {
+	for {
+		client, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+
+		if err != nil {
+			slog.Warn("TCP proxy accept failed", "error", err)
+			continue
+		}
+
+		go p.handle(client)
+	}
+}
+
+func (p *TCPProxy) handle(client net.Conn) {
+	addr := fmt.Sprintf("localhost:%d", p.targetPort)
+	upstream, err := net.DialTimeout("tcp", addr, upstreamDialTimeout)
+	if err != nil {
+		slog.Warn("TCP proxy failed to connect to application", "addr", addr, "error", err)
+		metrics.TCPProxyUpstreamErrors.Inc()
+		resetConn(client)
+		return
+	}
+
+	session := &tcpSession{
+		client:   client,
+		upstream: upstream,
+		start:    time.Now(),
+	}
+	p.track(session)
+	defer p.untrack(session)
+
+	done := make(chan struct{})
+	go func() {
+		pipe(upstream, client, "to_app")
+		close(done)
+	}()
+
+	pipe(client, upstream, "to_client")
+	<-done
+
+	client.Close()
+	upstream.Close()
+}
+
+// pipe copies src to dst and passes the end of stream on as a half-close,
+// so either side can finish sending while still reading.
+func pipe(dst net.Conn, src net.Conn, direction string) {
+	n, _ := io.Copy(dst, src)
+	closeWrite(dst)
+
+	metrics.TCPProxyBytes.WithLabelValues(direction).Add(float64(n))
+	metrics.TCPProxyConnectionBytes.WithLabelValues(direction).Observe(float64(n))
+}
+
+func (p *TCPProxy) track(session *tcpSession) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sessions[session] = struct{}{}
+	metrics.TCPProxyConnections.Inc()
+	metrics.TCPProxyActiveConnections.Set(float64(len(p.sessions)))
+}
+
+func (p *TCPProxy) untrack(session *tcpSession) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.sessions, session)
+	metrics.TCPProxyActiveConnections.Set(float64(len(p.sessions)))
+	metrics.TCPProxyConnectionDuration.Observe(time.Since(session.start).Seconds())
+}
+
+func (p *TCPProxy) closeRemaining() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.sessions) == 0 {
+		return
+	}
+
+	slog.Info("TCP proxy drain grace period over, closing connections", "open_connections", len(p.sessions), "close_mode", p.closeMode)
+
+	for session := range p.sessions {
+		if p.closeMode == CloseModeReset {
+			resetConn(session.client)
+			resetConn(session.upstream)
+		} else {
+			closeWrite(session.client)
+			closeWrite(session.upstream)
+		}
+
+		metrics.TCPProxyDrainClosed.WithLabelValues(string(p.closeMode)).Inc()
+	}
+
+	if p.closeMode == CloseModeHalfClose {
+		p.drainTimer = time.AfterFunc(p.halfCloseTimeout, p.resetRemaining)
+	}
+}
+
+// resetRemaining resets connections whose peers ignored the half-close.
+func (p *TCPProxy) resetRemaining() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.sessions) == 0 {
+		return
+	}
+
+	slog.Warn("TCP proxy connections still open after half-close, resetting", "open_connections", len(p.sessions), "timeout", p.halfCloseTimeout)
+
+	for session := range p.sessions {
+		resetConn(session.client)
+		resetConn(session.upstream)
+	}
+}
+
+func closeWrite(conn net.Conn) {
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.CloseWrite()
+		return
+	}
+	conn.Close()
+}
+
+// resetConn closes conn with a RST instead of a FIN.
+func resetConn(conn net.Conn) {
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetLinger(0)
+	}
+	conn.Close()
+}
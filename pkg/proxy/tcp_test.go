@@ -0,0 +1,270 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startEchoBackend echoes every line back until the client closes its side.
+func startEchoBackend(t *testing.T) uint16 {
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	return uint16(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// startHoldingBackend accepts connections and keeps them open, ignoring the
+// end of stream.
+func startHoldingBackend(t *testing.T) uint16 {
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	return uint16(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func dialProxy(t *testing.T, port uint16) net.Conn {
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func startTCPProxy(t *testing.T, targetPort uint16) (*TCPProxy, uint16) {
+	listenPort := getAvailablePort()
+	p := NewTCPProxy(listenPort, targetPort)
+	assert.NoError(t, p.Start())
+	t.Cleanup(func() { p.Close() })
+
+	return p, listenPort
+}
+
+func TestNewTCPProxy(t *testing.T) {
+	p := NewTCPProxy(9000, 8080)
+
+	assert.Equal(t, uint16(9000), p.listenPort)
+	assert.Equal(t, uint16(8080), p.targetPort)
+	assert.Equal(t, 30*time.Second, p.drainGrace)
+	assert.Equal(t, CloseModeHalfClose, p.closeMode)
+	assert.Equal(t, 5*time.Second, p.halfCloseTimeout)
+	assert.Equal(t, 0, p.InFlight())
+}
+
+func TestTCPProxy_ForwardsAndCountsConnections(t *testing.T) {
+	p, port := startTCPProxy(t, startEchoBackend(t))
+
+	conn := dialProxy(t, port)
+	fmt.Fprintln(conn, "ping")
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "ping\n", line)
+	assert.Equal(t, 1, p.InFlight())
+
+	conn.Close()
+	assert.Eventually(t, func() bool { return p.InFlight() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestTCPProxy_PropagatesHalfClose(t *testing.T) {
+	_, port := startTCPProxy(t, startEchoBackend(t))
+
+	conn := dialProxy(t, port)
+	fmt.Fprint(conn, "last words")
+	conn.(*net.TCPConn).CloseWrite()
+
+	data, err := io.ReadAll(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, "last words", string(data))
+}
+
+func TestTCPProxy_DrainingStopsAccepting(t *testing.T) {
+	p, port := startTCPProxy(t, startEchoBackend(t))
+	p.SetDrainGrace(time.Minute, CloseModeHalfClose)
+
+	existing := dialProxy(t, port)
+	assert.Eventually(t, func() bool { return p.InFlight() == 1 }, time.Second, 10*time.Millisecond)
+
+	p.SetDraining()
+	p.SetDraining()
+
+	_, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	assert.Error(t, err, "new connections are refused while draining")
+
+	fmt.Fprintln(existing, "still here")
+	line, err := bufio.NewReader(existing).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "still here\n", line)
+}
+
+func TestTCPProxy_HalfCloseAfterGrace(t *testing.T) {
+	p, port := startTCPProxy(t, startEchoBackend(t))
+	p.SetDrainGrace(50*time.Millisecond, CloseModeHalfClose)
+
+	conn := dialProxy(t, port)
+	assert.Eventually(t, func() bool { return p.InFlight() == 1 }, time.Second, 10*time.Millisecond)
+
+	p.SetDraining()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err := io.ReadAll(conn)
+	assert.NoError(t, err, "client sees a clean end of stream")
+
+	conn.Close()
+	assert.Eventually(t, func() bool { return p.InFlight() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestTCPProxy_ResetAfterIgnoredHalfClose(t *testing.T) {
+	p, port := startTCPProxy(t, startHoldingBackend(t))
+	p.SetDrainGrace(50*time.Millisecond, CloseModeHalfClose)
+	p.halfCloseTimeout = 100 * time.Millisecond
+
+	dialProxy(t, port)
+	assert.Eventually(t, func() bool { return p.InFlight() == 1 }, time.Second, 10*time.Millisecond)
+
+	p.SetDraining()
+
+	assert.Eventually(t, func() bool { return p.InFlight() == 0 }, 2*time.Second, 10*time.Millisecond, "a connection left open after the half-close is reset")
+}
+
+func TestTCPProxy_CloseStopsDrainTimer(t *testing.T) {
+	p, port := startTCPProxy(t, startEchoBackend(t))
+	p.SetDrainGrace(time.Minute, CloseModeHalfClose)
+
+	dialProxy(t, port)
+	assert.Eventually(t, func() bool { return p.InFlight() == 1 }, time.Second, 10*time.Millisecond)
+
+	p.SetDraining()
+	p.Close()
+
+	assert.False(t, p.drainTimer.Stop(), "the drain timer was already stopped")
+}
+
+func TestTCPProxy_ResetAfterGrace(t *testing.T) {
+	p, port := startTCPProxy(t, startEchoBackend(t))
+	p.SetDrainGrace(50*time.Millisecond, CloseModeReset)
+
+	conn := dialProxy(t, port)
+	assert.Eventually(t, func() bool { return p.InFlight() == 1 }, time.Second, 10*time.Millisecond)
+
+	p.SetDraining()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err := io.ReadAll(conn)
+	assert.True(t, errors.Is(err, syscall.ECONNRESET), "expected connection reset, got %v", err)
+	assert.Eventually(t, func() bool { return p.InFlight() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestTCPProxy_Shutdown_WaitsForConnections(t *testing.T) {
+	p, port := startTCPProxy(t, startEchoBackend(t))
+
+	conn := dialProxy(t, port)
+	assert.Eventually(t, func() bool { return p.InFlight() == 1 }, time.Second, 10*time.Millisecond)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		conn.Close()
+	}()
+
+	err := p.Shutdown(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, p.InFlight())
+	_, err = net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	assert.Error(t, err, "the listener is closed")
+}
+
+func TestTCPProxy_Shutdown_ResetsOnTimeout(t *testing.T) {
+	p, port := startTCPProxy(t, startEchoBackend(t))
+
+	conn := dialProxy(t, port)
+	assert.Eventually(t, func() bool { return p.InFlight() == 1 }, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := p.Shutdown(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = io.ReadAll(conn)
+	assert.True(t, errors.Is(err, syscall.ECONNRESET), "expected connection reset, got %v", err)
+}
+
+func TestTCPProxy_UpstreamUnavailable(t *testing.T) {
+	p, port := startTCPProxy(t, getAvailablePort())
+
+	// The reset can arrive before the dial returns.
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	if err == nil {
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, err = conn.Read(make([]byte, 1))
+	}
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, p.InFlight())
+}
+
+func TestTCPProxy_Start_PortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	p := NewTCPProxy(uint16(listener.Addr().(*net.TCPAddr).Port), getAvailablePort())
+
+	err = p.Start()
+	assert.ErrorContains(t, err, "failed to listen on proxy port")
+}
+
+func TestTCPProxy_Close_WithoutStart(t *testing.T) {
+	p := NewTCPProxy(getAvailablePort(), getAvailablePort())
+
+	assert.NoError(t, p.Close())
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import "time"
+
+// Budget splits one total shutdown time between the drain phase and the stop
+// phase, for platforms such as Kubernetes that kill the container after a
+// fixed grace period. The stop phase gets whatever the drain leaves unused.
+type Budget struct {
+	Total        time.Duration
+	DrainPercent int
+	DrainMin     time.Duration
+	StopMin      time.Duration
+}
+
+// Enabled reports whether the budget replaces DrainTimeout and
+// ShutdownTimeout.
+func (b Budget) Enabled() bool {
+	return b.Total > 0
+}
+
+// DrainLimit is the longest the drain phase may run: DrainPercent of the
+// total, raised to DrainMin and lowered to leave StopMin for the stop phase.
+func (b Budget) DrainLimit() time.Duration {
+	limit := b.Total * time.Duration(b.DrainPercent) / 100
+	limit = max(limit, b.DrainMin)
+	limit = min(limit, b.Total-b.StopMin)
+	return max(limit, 0)
+}
+
+// StopLimit is the least time the stop phase gets, when the drain uses its
+// whole share.
+func (b Budget) StopLimit() time.Duration {
+	return b.Total - b.DrainLimit()
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget_DrainLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		budget    Budget
+		wantDrain time.Duration
+		wantStop  time.Duration
+	}{
+		{
+			name:      "percentage",
+			budget:    Budget{Total: 30 * time.Second, DrainPercent: 80},
+			wantDrain: 24 * time.Second,
+			wantStop:  6 * time.Second,
+		},
+		{
+			name:      "drain minimum raises the share",
+			budget:    Budget{Total: 30 * time.Second, DrainPercent: 50, DrainMin: 20 * time.Second},
+			wantDrain: 20 * time.Second,
+			wantStop:  10 * time.Second,
+		},
+		{
+			name:      "stop minimum lowers the share",
+			budget:    Budget{Total: 30 * time.Second, DrainPercent: 90, StopMin: 10 * time.Second},
+			wantDrain: 20 * time.Second,
+			wantStop:  10 * time.Second,
+		},
+		{
+			name:      "stop minimum wins over drain minimum",
+			budget:    Budget{Total: 30 * time.Second, DrainPercent: 50, DrainMin: 25 * time.Second, StopMin: 10 * time.Second},
+			wantDrain: 20 * time.Second,
+			wantStop:  10 * time.Second,
+		},
+		{
+			name:      "stop minimum larger than total",
+			budget:    Budget{Total: 5 * time.Second, DrainPercent: 50, StopMin: 10 * time.Second},
+			wantDrain: 0,
+			wantStop:  5 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantDrain, tt.budget.DrainLimit())
+			assert.Equal(t, tt.wantStop, tt.budget.StopLimit())
+		})
+	}
+}
+
+func TestBudget_Enabled(t *testing.T) {
+	assert.False(t, Budget{}.Enabled())
+	assert.True(t, Budget{Total: time.Second}.Enabled())
+}
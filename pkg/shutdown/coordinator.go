@@ -15,34 +15,72 @@
 package shutdown
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"os"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/jpasei/zerohalt/pkg/health"
+	"github.com/jpasei/zerohalt/pkg/hooks"
 	"github.com/jpasei/zerohalt/pkg/metrics"
 	"github.com/jpasei/zerohalt/pkg/process"
 )
 
 var (
 	ErrShutdownTimeout = errors.New("shutdown timeout reached")
+	ErrNoDrain         = errors.New("no drain in progress")
+	ErrAppSignalled    = errors.New("application already signalled to stop")
+
+	// ErrDrainCancelled is returned by InitiateShutdown and Drain when the
+	// drain is cancelled. It is process.ErrShutdownCancelled, so the process
+	// manager keeps running the application.
+	ErrDrainCancelled = process.ErrShutdownCancelled
 )
 
 type HealthServer interface {
 	SetState(state health.HealthState)
+	ResumeFromDraining() bool
 }
 
+// RepeatSignalAction decides what a shutdown signal received while a
+// shutdown is already running does.
+type RepeatSignalAction string
+
+const (
+	// RepeatSignalIgnore leaves the running shutdown alone.
+	RepeatSignalIgnore RepeatSignalAction = "ignore"
+	// RepeatSignalExpedite skips the rest of the drain and signals the app.
+	RepeatSignalExpedite RepeatSignalAction = "expedite"
+	// RepeatSignalKill expedites on the second signal and sends SIGKILL on
+	// the third.
+	RepeatSignalKill RepeatSignalAction = "kill"
+)
+
 type ConnectionMonitor interface {
-	WaitForZeroConnections(timeout interface{}) error
+	WaitForZeroConnections(ctx context.Context, timeout time.Duration) error
 }
 
+// ShutdownConfig holds the shutdown budget and the hooks run within it.
+// Pre-drain hooks share DrainTimeout with the drain delay and the connection
+// wait. Pre-stop and post-stop hooks share ShutdownTimeout with the
+// application's exit, so pre-stop hooks still run after a drain timeout. A
+// Budget, when enabled, replaces both timeouts.
 type ShutdownConfig struct {
 	DrainTimeout          time.Duration
+	DrainDelay            time.Duration
 	ShutdownTimeout       time.Duration
 	SignalToApp           string
 	ForceKillAfterTimeout bool
+	PreDrainHooks         []hooks.Hook
+	PreStopHooks          []hooks.Hook
+	PostStopHooks         []hooks.Hook
+	DrainNotify           DrainNotifyConfig
+	RepeatSignalAction    RepeatSignalAction
+	Budget                Budget
+	Timeline              TimelineConfig
 }
 
 type Coordinator struct {
@@ -50,6 +88,33 @@ type Coordinator struct {
 	healthServer HealthServer
 	connMonitor  ConnectionMonitor
 	appProcess   *os.Process
+	appExit      *process.AppExit
+
+	// listenerFiles are zerohalt's copies of the socket activation
+	// listeners, closed once the application is stopped for good.
+	listenerFiles []*os.File
+
+	mu            sync.Mutex
+	current       *drainRun
+	repeatSignals int
+
+	// cancelMu keeps a drain from starting while another is being
+	// cancelled, so a shutdown signal never joins a cancelled drain.
+	cancelMu sync.Mutex
+}
+
+// drainRun is one drain, from its start until the application is signalled
+// or the drain is cancelled.
+type drainRun struct {
+	timeline     *Timeline
+	cancel       context.CancelFunc
+	stopSampling context.CancelFunc
+	cancelHooks  context.CancelFunc
+	done         chan struct{}
+	err          error
+	cancelled    bool
+	expedited    bool
+	appSignalled bool
 }
 
 func NewCoordinator(
@@ -66,24 +131,258 @@ func NewCoordinator(
 	}
 }
 
-func (c *Coordinator) SetAppProcess(appProcess *os.Process) {
+// SetAppProcess sets the application to stop. exit, if not nil, is the
+// instance's existing exit watcher, so the coordinator does not race it for
+// the exit status.
+func (c *Coordinator) SetAppProcess(appProcess *os.Process, exit *process.AppExit) {
 	c.appProcess = appProcess
+	c.appExit = exit
+}
+
+// SetListenerFiles sets the socket activation listeners to close when the
+// application is sent its final signal. Until then they keep accepting into
+// the kernel backlog, which is what a restart needs, but after it they would
+// only queue connections nobody will accept.
+func (c *Coordinator) SetListenerFiles(files []*os.File) {
+	c.listenerFiles = files
 }
 
-func (c *Coordinator) InitiateShutdown(sig os.Signal) error {
+// InitiateShutdown drains connections and stops the application. A drain
+// already started by Drain is joined rather than restarted. Cancelling ctx
+// cuts the drain short and stops waiting for the application to exit. When
+// it finishes, the shutdown's timeline is reported if enabled. If the drain
+// is cancelled with CancelDrain, it returns ErrDrainCancelled and leaves the
+// application running.
+func (c *Coordinator) InitiateShutdown(ctx context.Context, sig os.Signal) error {
 	slog.Info("Received signal, starting graceful shutdown", "signal", sig.String())
 
+	run := c.startDrain(ctx, "signal")
+	run.timeline.setSignal(sig)
+	c.waitForDrain(ctx, run)
+
+	stopCtx, cancelStop := c.stopContext(ctx, run.timeline.Start)
+	defer cancelStop()
+
+	hooksCtx, cancelHooks := context.WithCancel(stopCtx)
+	defer cancelHooks()
+
+	c.mu.Lock()
+	cancelled := run.cancelled
+	expedited := run.expedited
+	run.appSignalled = !cancelled
+	run.cancelHooks = cancelHooks
+	c.mu.Unlock()
+
+	if cancelled {
+		slog.Info("Shutdown cancelled, application stays in service", "signal", sig.String())
+		return ErrDrainCancelled
+	}
+
+	if !expedited {
+		c.runHooks(hooksCtx, run.timeline, "pre_stop_hooks", c.config.PreStopHooks)
+	}
+
+	err := c.stopApp(ctx, stopCtx, sig, run.timeline)
+	run.stopSampling()
+
+	run.timeline.finish(err)
+	if c.config.Timeline.Enabled {
+		c.reportTimeline(run.timeline)
+	}
+	return err
+}
+
+// Drain runs the drain phase without stopping the application, for callers
+// such as a Kubernetes preStop hook that run before the shutdown signal. It
+// returns once connections have drained or the drain timeout expires, or
+// with ctx's error if ctx ends first; the drain itself keeps running. A later
+// InitiateShutdown joins it.
+func (c *Coordinator) Drain(ctx context.Context) error {
+	slog.Info("Drain requested before shutdown signal")
+
+	run := c.startDrain(context.Background(), "prestop")
+
+	select {
+	case <-run.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if run.cancelled {
+		return ErrDrainCancelled
+	}
+	return run.err
+}
+
+// CancelDrain stops a drain and returns the health check to service. It is
+// refused once the application has been signalled, since the application
+// cannot be un-stopped. source names who asked, for logs and metrics.
+func (c *Coordinator) CancelDrain(source string) error {
+	c.cancelMu.Lock()
+	defer c.cancelMu.Unlock()
+
+	c.mu.Lock()
+	run := c.current
+
+	var err error
+	switch {
+	case run == nil:
+		err = ErrNoDrain
+	case run.appSignalled:
+		err = ErrAppSignalled
+	}
+	if err != nil {
+		c.mu.Unlock()
+		slog.Warn("Drain cancellation rejected", "source", source, "reason", err)
+		metrics.DrainCancellations.WithLabelValues(source, "rejected").Inc()
+		return err
+	}
+
+	run.cancelled = true
+	c.mu.Unlock()
+
+	slog.Warn("Cancelling drain", "source", source, "trigger", run.timeline.Trigger)
+	run.cancel()
+	<-run.done
+	run.stopSampling()
+
+	c.mu.Lock()
+	c.current = nil
+	c.repeatSignals = 0
+	c.mu.Unlock()
+
+	c.clearDrainNotify()
+	c.healthServer.ResumeFromDraining()
+
+	run.timeline.finish(ErrDrainCancelled)
+	if c.config.Timeline.Enabled {
+		c.reportTimeline(run.timeline)
+	}
+
+	metrics.DrainCancellations.WithLabelValues(source, "cancelled").Inc()
+	slog.Info("Drain cancelled, back in service", "source", source)
+	return nil
+}
+
+// startDrain starts the drain phase in the background, or returns the one
+// already running. The drain timeout, or budget, counts from the first call.
+func (c *Coordinator) startDrain(ctx context.Context, trigger string) *drainRun {
+	c.cancelMu.Lock()
+	defer c.cancelMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.current != nil {
+		slog.Info("Joining drain already in progress", "trigger", c.current.timeline.Trigger)
+		return c.current
+	}
+
+	drainCtx, cancelDrain := context.WithTimeout(ctx, c.drainTimeout())
+	sampleCtx, stopSampling := context.WithCancel(context.Background())
+
+	run := &drainRun{
+		timeline:     newTimeline(trigger),
+		cancel:       cancelDrain,
+		stopSampling: stopSampling,
+		done:         make(chan struct{}),
+	}
+	c.current = run
+
+	if c.repeatSignals > 0 {
+		run.expedited = true
+		cancelDrain()
+	}
+
+	if counter, ok := c.connMonitor.(ConnectionCounter); ok && c.config.Timeline.Enabled {
+		go run.timeline.sampleConnections(sampleCtx, counter)
+	}
+
+	go func() {
+		defer close(run.done)
+		defer cancelDrain()
+
+		err := c.drain(drainCtx, run)
+
+		c.mu.Lock()
+		run.err = err
+		c.mu.Unlock()
+	}()
+
+	return run
+}
+
+// waitForDrain waits for the drain to end. Cancelling ctx cuts it short,
+// even when it was started by Drain.
+func (c *Coordinator) waitForDrain(ctx context.Context, run *drainRun) {
+	select {
+	case <-run.done:
+		return
+	case <-ctx.Done():
+	}
+
+	run.cancel()
+	<-run.done
+}
+
+// isCancelled reports whether CancelDrain stopped run, in which case the
+// remaining drain steps are skipped.
+func (c *Coordinator) isCancelled(run *drainRun) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return run.cancelled
+}
+
+func (c *Coordinator) drain(drainCtx context.Context, run *drainRun) error {
+	timeline := run.timeline
+
+	if budget := c.config.Budget; budget.Enabled() {
+		slog.Info("Shutdown budget allocated", "total", budget.Total, "drain", budget.DrainLimit(), "stop_min", budget.StopLimit())
+	}
+
+	c.notifyDrain(drainCtx)
+	c.runHooks(drainCtx, timeline, "pre_drain_hooks", c.config.PreDrainHooks)
+
+	if c.isCancelled(run) {
+		return ErrDrainCancelled
+	}
+
 	c.healthServer.SetState(health.StateDraining)
 	metrics.HealthApp.Set(float64(health.StateDraining))
 
 	slog.Info("Health check now returning 503")
 
-	err := c.connMonitor.WaitForZeroConnections(c.config.DrainTimeout)
+	if c.config.DrainDelay > 0 {
+		endPhase := timeline.phase("drain_delay")
+		c.waitDrainDelay(drainCtx)
+		endPhase(nil)
+	}
+
+	waitStart := time.Now()
+	slog.Info("Drain phase: waiting for connections to close", "timeout", remaining(drainCtx))
+
+	endPhase := timeline.phase("connection_drain")
+	err := c.connMonitor.WaitForZeroConnections(drainCtx, remaining(drainCtx))
+	endPhase(err)
 	if err != nil {
-		slog.Warn("Connection drain timeout", "error", err)
+		slog.Warn("Connection drain timeout", "error", err, "duration", time.Since(waitStart))
 	} else {
-		slog.Info("All connections drained")
+		slog.Info("All connections drained", "duration", time.Since(waitStart))
+	}
+
+	if c.isCancelled(run) {
+		return ErrDrainCancelled
 	}
+	return err
+}
+
+// stopApp signals the application and waits for it to exit within stopCtx,
+// the stop phase's share of the shutdown. ctx is the caller's context.
+func (c *Coordinator) stopApp(ctx context.Context, stopCtx context.Context, sig os.Signal, timeline *Timeline) error {
+	c.closeListenerFiles()
 
 	if c.appProcess == nil {
 		slog.Info("No application process to signal")
@@ -94,35 +393,150 @@ func (c *Coordinator) InitiateShutdown(sig os.Signal) error {
 	if err := c.appProcess.Signal(signal); err != nil {
 		slog.Error("Error sending signal to app", "error", err)
 	} else {
+		timeline.signalSent(signal, c.appProcess.Pid)
 		slog.Info("Sent signal to application", "signal", signal.String(), "pid", c.appProcess.Pid)
 	}
 
-	done := make(chan error, 1)
-	go func() {
-		_, err := c.appProcess.Wait()
-		if err != nil {
-			errMsg := err.Error()
-			if errMsg == "waitid: no child processes" || errMsg == "wait: no child processes" {
-				done <- nil
-				return
-			}
-		}
-		done <- err
-	}()
+	endPhase := timeline.phase("app_exit")
+
+	exit := c.appExit
+	if exit == nil {
+		exit = process.WatchExit(c.appProcess)
+	}
 
 	select {
-	case err := <-done:
+	case <-exit.Done():
+		state, err := exit.Result()
+		timeline.exited(state)
+		endPhase(err)
 		slog.Info("Application exited cleanly")
+		c.runHooks(stopCtx, timeline, "post_stop_hooks", c.config.PostStopHooks)
 		return err
-	case <-time.After(c.config.ShutdownTimeout):
+	case <-stopCtx.Done():
+		if ctx.Err() != nil {
+			endPhase(ctx.Err())
+			slog.Warn("Stopped waiting for application to exit", "reason", ctx.Err())
+			return ctx.Err()
+		}
+		endPhase(ErrShutdownTimeout)
 		if c.config.ForceKillAfterTimeout {
 			c.appProcess.Signal(syscall.SIGKILL)
+			timeline.signalSent(syscall.SIGKILL, c.appProcess.Pid)
 			slog.Warn("Sent SIGKILL after timeout")
 		}
+		if len(c.config.PostStopHooks) > 0 {
+			slog.Warn("Skipping post-stop hooks, shutdown timeout exhausted")
+		}
 		return ErrShutdownTimeout
 	}
 }
 
+// closeListenerFiles closes zerohalt's copies of the listeners, so the ports
+// refuse connections once the application closes its own.
+func (c *Coordinator) closeListenerFiles() {
+	for _, file := range c.listenerFiles {
+		file.Close()
+	}
+	c.listenerFiles = nil
+}
+
+// runHooks runs a phase's hooks, recording the phase only when there are
+// hooks to run.
+func (c *Coordinator) runHooks(ctx context.Context, timeline *Timeline, phase string, phaseHooks []hooks.Hook) {
+	if len(phaseHooks) == 0 {
+		return
+	}
+
+	endPhase := timeline.phase(phase)
+	hooks.RunEach(ctx, phaseHooks)
+	endPhase(nil)
+}
+
+func (c *Coordinator) drainTimeout() time.Duration {
+	if c.config.Budget.Enabled() {
+		return c.config.Budget.DrainLimit()
+	}
+	return c.config.DrainTimeout
+}
+
+// stopContext bounds the stop phase. Under a budget it ends when the total
+// budget, counted from start, runs out, so time the drain left unused rolls
+// over to the application's exit.
+func (c *Coordinator) stopContext(ctx context.Context, start time.Time) (context.Context, context.CancelFunc) {
+	if c.config.Budget.Enabled() {
+		return context.WithDeadline(ctx, start.Add(c.config.Budget.Total))
+	}
+	return context.WithTimeout(ctx, c.config.ShutdownTimeout)
+}
+
+// waitDrainDelay gives load balancers time to notice the 503 and stop
+// sending new requests before connections are counted. The delay is cut
+// short if the drain budget runs out first.
+func (c *Coordinator) waitDrainDelay(drainCtx context.Context) {
+	delay := c.config.DrainDelay
+	if delay <= 0 {
+		return
+	}
+
+	slog.Info("Drain phase: waiting for load balancers to stop routing", "delay", delay)
+
+	select {
+	case <-time.After(delay):
+	case <-drainCtx.Done():
+		slog.Warn("Drain delay cut short", "reason", drainCtx.Err())
+	}
+}
+
+// remaining returns the time left before ctx expires, never less than zero.
+func remaining(ctx context.Context) time.Duration {
+	deadline, _ := ctx.Deadline()
+	return max(time.Until(deadline), 0)
+}
+
+// HandleRepeatSignal reacts to a shutdown signal that arrives while
+// InitiateShutdown is running, according to RepeatSignalAction.
+func (c *Coordinator) HandleRepeatSignal(sig os.Signal) {
+	action := c.config.RepeatSignalAction
+
+	c.mu.Lock()
+	c.repeatSignals++
+	isFirstRepeat := c.repeatSignals == 1
+	shouldExpedite := isFirstRepeat && (action == RepeatSignalExpedite || action == RepeatSignalKill)
+	shouldKill := !isFirstRepeat && action == RepeatSignalKill
+
+	run := c.current
+	var cancelHooks context.CancelFunc
+	if run != nil && shouldExpedite {
+		run.expedited = true
+		cancelHooks = run.cancelHooks
+	}
+	c.mu.Unlock()
+
+	switch {
+	case shouldExpedite:
+		slog.Warn("Repeated shutdown signal, skipping remaining drain", "signal", sig.String())
+		if run != nil {
+			run.cancel()
+		}
+		if cancelHooks != nil {
+			cancelHooks()
+		}
+
+	case shouldKill:
+		if c.appProcess == nil {
+			return
+		}
+		slog.Warn("Repeated shutdown signal, sending SIGKILL", "signal", sig.String(), "pid", c.appProcess.Pid)
+		c.appProcess.Signal(syscall.SIGKILL)
+		if run != nil {
+			run.timeline.signalSent(syscall.SIGKILL, c.appProcess.Pid)
+		}
+
+	default:
+		slog.Info("Shutdown already in progress, ignoring signal", "signal", sig.String())
+	}
+}
+
 func (c *Coordinator) getSignalForApp(receivedSignal os.Signal) os.Signal {
 	signalToAppIsEmpty := c.config.SignalToApp == ""
 
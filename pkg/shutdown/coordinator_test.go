@@ -15,15 +15,21 @@
 package shutdown
 
 import (
+	"context"
+	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
 
 	"github.com/jpasei/zerohalt/pkg/health"
+	"github.com/jpasei/zerohalt/pkg/hooks"
 	"github.com/jpasei/zerohalt/pkg/metrics"
 	"github.com/jpasei/zerohalt/pkg/monitor"
+	"github.com/jpasei/zerohalt/pkg/process"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
@@ -36,11 +42,19 @@ func (m *mockHealthServer) SetState(state health.HealthState) {
 	m.state = state
 }
 
+func (m *mockHealthServer) ResumeFromDraining() bool {
+	if m.state != health.StateDraining {
+		return false
+	}
+	m.state = health.StateHealthy
+	return true
+}
+
 type mockConnectionMonitor struct {
 	shouldTimeout bool
 }
 
-func (m *mockConnectionMonitor) WaitForZeroConnections(timeout interface{}) error {
+func (m *mockConnectionMonitor) WaitForZeroConnections(ctx context.Context, timeout time.Duration) error {
 	return nil
 }
 
@@ -62,7 +76,7 @@ func TestCoordinator_SetAppProcess(t *testing.T) {
 	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
 
 	process := &os.Process{Pid: 123}
-	coordinator.SetAppProcess(process)
+	coordinator.SetAppProcess(process, nil)
 
 	assert.Equal(t, process, coordinator.appProcess)
 }
@@ -76,7 +90,7 @@ func TestCoordinator_InitiateShutdown_NoProcess(t *testing.T) {
 	connMonitor := &mockConnectionMonitor{}
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, nil)
 
-	err := coordinator.InitiateShutdown(syscall.SIGTERM)
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
 
 	assert.NoError(t, err)
 	assert.Equal(t, health.StateDraining, healthServer.state)
@@ -92,7 +106,7 @@ func TestCoordinator_InitiateShutdown_DrainTimeout(t *testing.T) {
 	connMonitor := &mockConnectionMonitor{shouldTimeout: true}
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, nil)
 
-	err := coordinator.InitiateShutdown(syscall.SIGTERM)
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
 
 	assert.NoError(t, err)
 }
@@ -142,12 +156,40 @@ func TestCoordinator_InitiateShutdown_WithProcessCleanExit(t *testing.T) {
 
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, cmd.Process)
 
-	err = coordinator.InitiateShutdown(syscall.SIGTERM)
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
 
 	assert.NoError(t, err)
 	assert.Equal(t, health.StateDraining, healthServer.state)
 }
 
+func TestCoordinator_InitiateShutdown_ClosesListenerFiles(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	assert.NoError(t, err)
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	files, err := process.OpenListeners([]uint16{port})
+	assert.NoError(t, err)
+
+	cfg := &ShutdownConfig{
+		DrainTimeout:    100 * time.Millisecond,
+		ShutdownTimeout: 2 * time.Second,
+		SignalToApp:     "SIGTERM",
+	}
+
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, cmd.Process)
+	coordinator.SetListenerFiles(files)
+
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err)
+
+	_, err = net.DialTimeout("tcp", listener.Addr().String(), time.Second)
+	assert.ErrorIs(t, err, syscall.ECONNREFUSED, "port should refuse connections once the application has stopped")
+}
+
 func TestCoordinator_InitiateShutdown_SignalError(t *testing.T) {
 	cfg := &ShutdownConfig{
 		DrainTimeout:    100 * time.Millisecond,
@@ -166,7 +208,7 @@ func TestCoordinator_InitiateShutdown_SignalError(t *testing.T) {
 
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, cmd.Process)
 
-	err = coordinator.InitiateShutdown(syscall.SIGTERM)
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
 
 	assert.NoError(t, err)
 	assert.Equal(t, health.StateDraining, healthServer.state)
@@ -193,7 +235,7 @@ func TestCoordinator_InitiateShutdown_Timeout(t *testing.T) {
 
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, cmd.Process)
 
-	err = coordinator.InitiateShutdown(syscall.SIGTERM)
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
 
 	assert.Equal(t, ErrShutdownTimeout, err)
 }
@@ -217,7 +259,7 @@ func TestCoordinator_InitiateShutdown_TimeoutWithForceKill(t *testing.T) {
 
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, cmd.Process)
 
-	err = coordinator.InitiateShutdown(syscall.SIGTERM)
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
 
 	assert.Equal(t, ErrShutdownTimeout, err)
 
@@ -234,7 +276,7 @@ func TestCoordinator_InitiateShutdown_DrainTimeoutWithMonitorError(t *testing.T)
 	connMonitor := &mockConnectionMonitorWithTimeout{shouldTimeout: true}
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, nil)
 
-	err := coordinator.InitiateShutdown(syscall.SIGTERM)
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
 
 	assert.NoError(t, err)
 }
@@ -243,7 +285,7 @@ type mockConnectionMonitorWithTimeout struct {
 	shouldTimeout bool
 }
 
-func (m *mockConnectionMonitorWithTimeout) WaitForZeroConnections(timeout interface{}) error {
+func (m *mockConnectionMonitorWithTimeout) WaitForZeroConnections(ctx context.Context, timeout time.Duration) error {
 	return monitor.ErrDrainTimeout
 }
 
@@ -265,7 +307,7 @@ func TestCoordinator_InitiateShutdown_WaitReturnsNoChildError(t *testing.T) {
 
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, cmd.Process)
 
-	err = coordinator.InitiateShutdown(syscall.SIGTERM)
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
 
 	assert.NoError(t, err)
 	assert.Equal(t, health.StateDraining, healthServer.state)
@@ -295,7 +337,7 @@ func TestCoordinator_InitiateShutdown_ForceKillOnTimeout(t *testing.T) {
 
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, cmd.Process)
 
-	err = coordinator.InitiateShutdown(syscall.SIGTERM)
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
 
 	assert.Equal(t, ErrShutdownTimeout, err)
 	assert.Equal(t, health.StateDraining, healthServer.state)
@@ -359,7 +401,7 @@ func TestCoordinator_InitiateShutdown_SetsHealthAppMetricToZeroImmediately(t *te
 	connMonitor := &mockConnectionMonitor{}
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, nil)
 
-	err := coordinator.InitiateShutdown(syscall.SIGTERM)
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
 
 	assert.NoError(t, err)
 
@@ -379,8 +421,588 @@ func TestCoordinator_InitiateShutdown_SetsStateToDraining(t *testing.T) {
 	connMonitor := &mockConnectionMonitor{}
 	coordinator := NewCoordinator(cfg, healthServer, connMonitor, nil)
 
-	err := coordinator.InitiateShutdown(syscall.SIGTERM)
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+
+	assert.NoError(t, err)
+	assert.Equal(t, health.StateDraining, healthServer.state)
+}
+
+// appendHook returns a hook that appends line to the file at path.
+func appendHook(name string, path string, line string) hooks.Hook {
+	return hooks.Hook{
+		Name:    name,
+		Command: []string{"sh", "-c", `echo "$1" >> "$0"`, path, line},
+		Timeout: 5 * time.Second,
+	}
+}
+
+type recordingHealthServer struct {
+	path string
+}
+
+func (r *recordingHealthServer) SetState(state health.HealthState) {
+	file, _ := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	defer file.Close()
+	file.WriteString(state.String() + "\n")
+}
+
+func (r *recordingHealthServer) ResumeFromDraining() bool {
+	r.SetState(health.StateHealthy)
+	return true
+}
+
+type recordingConnectionMonitor struct {
+	timeout time.Duration
+}
+
+func (r *recordingConnectionMonitor) WaitForZeroConnections(ctx context.Context, timeout time.Duration) error {
+	r.timeout = timeout
+	return nil
+}
+
+func TestCoordinator_InitiateShutdown_HookOrder(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "order")
+
+	cfg := &ShutdownConfig{
+		DrainTimeout:    5 * time.Second,
+		ShutdownTimeout: 5 * time.Second,
+		SignalToApp:     "SIGTERM",
+		PreDrainHooks:   []hooks.Hook{appendHook("pre-drain-1", log, "pre-drain")},
+		PreStopHooks:    []hooks.Hook{appendHook("pre-stop-1", log, "pre-stop")},
+		PostStopHooks:   []hooks.Hook{appendHook("post-stop-1", log, "post-stop")},
+	}
+
+	cmd := exec.Command("sleep", "10")
+	err := cmd.Start()
+	assert.NoError(t, err)
+
+	coordinator := NewCoordinator(cfg, &recordingHealthServer{path: log}, &mockConnectionMonitor{}, cmd.Process)
+
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err)
+
+	order, _ := os.ReadFile(log)
+	assert.Equal(t, "pre-drain\ndraining\npre-stop\npost-stop\n", string(order))
+}
+
+func TestCoordinator_InitiateShutdown_HooksCountAgainstDrainTimeout(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:    200 * time.Millisecond,
+		ShutdownTimeout: time.Second,
+		PreDrainHooks: []hooks.Hook{
+			{Name: "pre-drain-1", Command: []string{"sleep", "10"}, Timeout: time.Minute},
+		},
+	}
+
+	connMonitor := &recordingConnectionMonitor{}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, nil)
+
+	start := time.Now()
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 3*time.Second, "a hook must not outlive the drain timeout")
+	assert.Zero(t, connMonitor.timeout, "the drain gets only what the hook left of the budget")
+}
+
+func TestCoordinator_InitiateShutdown_PreStopHooksRunAfterDrainTimeout(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "order")
+
+	cfg := &ShutdownConfig{
+		DrainTimeout:    100 * time.Millisecond,
+		ShutdownTimeout: 5 * time.Second,
+		PreStopHooks:    []hooks.Hook{appendHook("pre-stop-1", log, "pre-stop")},
+	}
+
+	connMonitor := &slowConnectionMonitor{delay: 5 * time.Second}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, nil)
+
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err)
+
+	order, _ := os.ReadFile(log)
+	assert.Equal(t, "pre-stop\n", string(order), "pre-stop hooks run from the stop phase's time, not the expired drain")
+}
+
+func TestCoordinator_InitiateShutdown_FailedHookDoesNotBlockShutdown(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "order")
+
+	cfg := &ShutdownConfig{
+		DrainTimeout:    time.Second,
+		ShutdownTimeout: time.Second,
+		PreStopHooks: []hooks.Hook{
+			{Name: "pre-stop-1", Command: []string{"false"}, Timeout: time.Second},
+			appendHook("pre-stop-2", log, "pre-stop-2"),
+		},
+	}
+
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
+
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err)
+
+	order, _ := os.ReadFile(log)
+	assert.Equal(t, "pre-stop-2\n", string(order))
+}
+
+func TestCoordinator_InitiateShutdown_SkipsPostStopHooksOnTimeout(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "post-stop")
+
+	cfg := &ShutdownConfig{
+		DrainTimeout:    100 * time.Millisecond,
+		ShutdownTimeout: 200 * time.Millisecond,
+		SignalToApp:     "SIGTERM",
+		PostStopHooks: []hooks.Hook{
+			{Name: "post-stop-1", Command: []string{"touch", marker}, Timeout: time.Second},
+		},
+	}
+
+	cmd := exec.Command("/bin/sh", "testdata/ignore_sigterm.sh")
+	err := cmd.Start()
+	assert.NoError(t, err)
+	defer cmd.Process.Kill()
+
+	time.Sleep(50 * time.Millisecond)
+
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, cmd.Process)
+
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.Equal(t, ErrShutdownTimeout, err)
+
+	_, statErr := os.Stat(marker)
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestCoordinator_InitiateShutdown_DrainDelay(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout: 2 * time.Second,
+		DrainDelay:   300 * time.Millisecond,
+	}
+
+	healthServer := &mockHealthServer{}
+	connMonitor := &recordingConnectionMonitor{}
+	coordinator := NewCoordinator(cfg, healthServer, connMonitor, nil)
+
+	start := time.Now()
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	assert.Equal(t, health.StateDraining, healthServer.state)
+	assert.LessOrEqual(t, connMonitor.timeout, 1700*time.Millisecond, "the delay counts against the drain timeout")
+	assert.Greater(t, connMonitor.timeout, time.Second)
+}
+
+// blockingConnectionMonitor waits out the drain until its context ends.
+type blockingConnectionMonitor struct {
+	started chan struct{}
+}
+
+func (b *blockingConnectionMonitor) WaitForZeroConnections(ctx context.Context, timeout time.Duration) error {
+	close(b.started)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+func TestCoordinator_HandleRepeatSignal_Expedite(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:       time.Minute,
+		ShutdownTimeout:    5 * time.Second,
+		SignalToApp:        "SIGTERM",
+		RepeatSignalAction: RepeatSignalExpedite,
+	}
+
+	cmd := exec.Command("sleep", "10")
+	err := cmd.Start()
+	assert.NoError(t, err)
+
+	connMonitor := &blockingConnectionMonitor{started: make(chan struct{})}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, cmd.Process)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	}()
+
+	<-connMonitor.started
+	coordinator.HandleRepeatSignal(syscall.SIGTERM)
+
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("a second signal should skip the rest of the drain")
+	}
+}
+
+func TestCoordinator_HandleRepeatSignal_Kill(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:       time.Minute,
+		ShutdownTimeout:    time.Minute,
+		SignalToApp:        "SIGTERM",
+		RepeatSignalAction: RepeatSignalKill,
+	}
+
+	cmd := exec.Command("/bin/sh", "testdata/ignore_sigterm.sh")
+	err := cmd.Start()
+	assert.NoError(t, err)
+	defer cmd.Process.Kill()
+
+	time.Sleep(50 * time.Millisecond)
+
+	connMonitor := &blockingConnectionMonitor{started: make(chan struct{})}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, cmd.Process)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	}()
+
+	<-connMonitor.started
+	coordinator.HandleRepeatSignal(syscall.SIGTERM)
+
+	// Give the expedited shutdown time to signal the app before killing it.
+	time.Sleep(100 * time.Millisecond)
+	coordinator.HandleRepeatSignal(syscall.SIGTERM)
+
+	select {
+	case <-result:
+	case <-time.After(3 * time.Second):
+		t.Fatal("a third signal should kill the application")
+	}
+}
+
+func TestCoordinator_HandleRepeatSignal_Ignore(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:       300 * time.Millisecond,
+		RepeatSignalAction: RepeatSignalIgnore,
+	}
+
+	connMonitor := &blockingConnectionMonitor{started: make(chan struct{})}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, nil)
+
+	result := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		result <- coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	}()
+
+	<-connMonitor.started
+	coordinator.HandleRepeatSignal(syscall.SIGTERM)
+
+	<-result
+	assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond, "the drain should run its course")
+}
+
+func TestCoordinator_HandleRepeatSignal_BeforeDrainStarts(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:       time.Minute,
+		RepeatSignalAction: RepeatSignalExpedite,
+	}
+
+	connMonitor := &blockingConnectionMonitor{started: make(chan struct{})}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, nil)
+	coordinator.HandleRepeatSignal(syscall.SIGTERM)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	}()
+
+	select {
+	case <-result:
+	case <-time.After(3 * time.Second):
+		t.Fatal("a repeat signal received before the drain started should still skip it")
+	}
+}
+
+func TestCoordinator_InitiateShutdown_ParentContextCancelled(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:          time.Minute,
+		ShutdownTimeout:       time.Minute,
+		SignalToApp:           "SIGTERM",
+		ForceKillAfterTimeout: true,
+	}
+
+	cmd := exec.Command("/bin/sh", "testdata/ignore_sigterm.sh")
+	err := cmd.Start()
+	assert.NoError(t, err)
+	defer cmd.Process.Kill()
+
+	time.Sleep(50 * time.Millisecond)
+
+	connMonitor := &blockingConnectionMonitor{started: make(chan struct{})}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, cmd.Process)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- coordinator.InitiateShutdown(ctx, syscall.SIGTERM)
+	}()
+
+	<-connMonitor.started
+	cancel()
+
+	select {
+	case err := <-result:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(3 * time.Second):
+		t.Fatal("cancelling the parent context should abort the shutdown")
+	}
+
+	assert.NoError(t, cmd.Process.Signal(syscall.Signal(0)), "the application must not be killed when the caller cancels")
+}
+
+func TestCoordinator_InitiateShutdown_BudgetLimitsDrain(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:    time.Minute,
+		ShutdownTimeout: time.Minute,
+		Budget:          Budget{Total: 10 * time.Second, DrainPercent: 30},
+	}
+
+	connMonitor := &recordingConnectionMonitor{}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, nil)
+
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, connMonitor.timeout, 3*time.Second)
+	assert.Greater(t, connMonitor.timeout, 2*time.Second)
+}
+
+func TestCoordinator_InitiateShutdown_BudgetRollsOverUnusedDrain(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:          time.Minute,
+		ShutdownTimeout:       time.Minute,
+		SignalToApp:           "SIGTERM",
+		ForceKillAfterTimeout: true,
+		Budget:                Budget{Total: 600 * time.Millisecond, DrainPercent: 80},
+	}
+
+	cmd := exec.Command("/bin/sh", "testdata/ignore_sigterm.sh")
+	err := cmd.Start()
+	assert.NoError(t, err)
+	defer cmd.Process.Kill()
+
+	time.Sleep(50 * time.Millisecond)
+
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, cmd.Process)
+
+	start := time.Now()
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	elapsed := time.Since(start)
+
+	assert.Equal(t, ErrShutdownTimeout, err)
+	assert.GreaterOrEqual(t, elapsed, 550*time.Millisecond, "the stop phase should get the drain's unused time")
+	assert.Less(t, elapsed, 2*time.Second, "the stop phase must end with the budget")
+}
+
+// slowConnectionMonitor takes delay to drain and counts how often it is
+// asked to.
+type slowConnectionMonitor struct {
+	mu    sync.Mutex
+	delay time.Duration
+	calls int
+}
+
+func (s *slowConnectionMonitor) WaitForZeroConnections(ctx context.Context, timeout time.Duration) error {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+
+	select {
+	case <-time.After(s.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *slowConnectionMonitor) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func TestCoordinator_Drain_BlocksUntilDrainedWithoutStoppingApp(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:    5 * time.Second,
+		ShutdownTimeout: 5 * time.Second,
+		SignalToApp:     "SIGTERM",
+	}
+
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	healthServer := &mockHealthServer{}
+	connMonitor := &slowConnectionMonitor{delay: 200 * time.Millisecond}
+	coordinator := NewCoordinator(cfg, healthServer, connMonitor, cmd.Process)
+
+	start := time.Now()
+	err := coordinator.Drain(context.Background())
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	assert.Equal(t, health.StateDraining, healthServer.state)
+	assert.NoError(t, cmd.Process.Signal(syscall.Signal(0)), "the application must keep running until the shutdown signal")
+}
+
+func TestCoordinator_InitiateShutdown_JoinsDrainInProgress(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:    5 * time.Second,
+		ShutdownTimeout: 5 * time.Second,
+		SignalToApp:     "SIGTERM",
+	}
+
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+
+	connMonitor := &slowConnectionMonitor{delay: 300 * time.Millisecond}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, cmd.Process)
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- coordinator.Drain(context.Background())
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+
+	assert.NoError(t, err)
+	assert.NoError(t, <-drained)
+	assert.Equal(t, 1, connMonitor.callCount(), "the shutdown should join the drain rather than start over")
+	assert.Less(t, time.Since(start), 300*time.Millisecond)
+}
+
+func TestCoordinator_Drain_ReturnsWhenContextEnds(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout: 5 * time.Second,
+	}
+
+	connMonitor := &slowConnectionMonitor{delay: time.Second}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := coordinator.Drain(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	err = coordinator.Drain(context.Background())
+	assert.NoError(t, err, "the drain keeps running after the caller gives up")
+	assert.Equal(t, 1, connMonitor.callCount())
+}
+
+func drainCancellationCount(source string, result string) float64 {
+	metric := &dto.Metric{}
+	metrics.DrainCancellations.WithLabelValues(source, result).Write(metric)
+	return metric.Counter.GetValue()
+}
+
+func TestCoordinator_CancelDrain_ReturnsToService(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "draining")
+	hookOutput := filepath.Join(t.TempDir(), "hooks")
+	cfg := &ShutdownConfig{
+		DrainTimeout:    5 * time.Second,
+		ShutdownTimeout: 5 * time.Second,
+		SignalToApp:     "SIGTERM",
+		PreStopHooks:    []hooks.Hook{appendHook("pre-stop", hookOutput, "pre-stop")},
+		DrainNotify:     DrainNotifyConfig{FilePath: marker},
+	}
+
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	healthServer := &mockHealthServer{}
+	connMonitor := &slowConnectionMonitor{delay: 5 * time.Second}
+	coordinator := NewCoordinator(cfg, healthServer, connMonitor, cmd.Process)
+	before := drainCancellationCount("admin", "cancelled")
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.FileExists(t, marker)
+
+	start := time.Now()
+	err := coordinator.CancelDrain("admin")
 
 	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, <-shutdownErr, ErrDrainCancelled)
+	assert.Equal(t, health.StateHealthy, healthServer.state)
+	assert.NoFileExists(t, marker)
+	assert.NoFileExists(t, hookOutput, "pre-stop hooks must not run for a cancelled drain")
+	assert.NoError(t, cmd.Process.Signal(syscall.Signal(0)), "the application must keep running")
+	assert.Equal(t, before+1, drainCancellationCount("admin", "cancelled"))
+}
+
+func TestCoordinator_CancelDrain_NoDrain(t *testing.T) {
+	coordinator := NewCoordinator(&ShutdownConfig{}, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
+	before := drainCancellationCount("signal", "rejected")
+
+	err := coordinator.CancelDrain("signal")
+
+	assert.ErrorIs(t, err, ErrNoDrain)
+	assert.Equal(t, before+1, drainCancellationCount("signal", "rejected"))
+}
+
+func TestCoordinator_CancelDrain_AfterAppSignalled(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:    time.Second,
+		ShutdownTimeout: 5 * time.Second,
+		SignalToApp:     "SIGTERM",
+	}
+
+	cmd := exec.Command("sh", "-c", "trap '' TERM; sleep 0.5")
+	assert.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	healthServer := &mockHealthServer{}
+	coordinator := NewCoordinator(cfg, healthServer, &mockConnectionMonitor{}, cmd.Process)
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	err := coordinator.CancelDrain("admin")
+
+	assert.ErrorIs(t, err, ErrAppSignalled)
+	assert.NoError(t, <-shutdownErr)
 	assert.Equal(t, health.StateDraining, healthServer.state)
 }
+
+func TestCoordinator_CancelDrain_NextSignalStartsNewDrain(t *testing.T) {
+	cfg := &ShutdownConfig{
+		DrainTimeout:    5 * time.Second,
+		ShutdownTimeout: 5 * time.Second,
+	}
+
+	connMonitor := &slowConnectionMonitor{delay: 200 * time.Millisecond}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, nil)
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- coordinator.Drain(context.Background())
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, coordinator.CancelDrain("admin"))
+	assert.ErrorIs(t, <-drained, ErrDrainCancelled)
+
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, connMonitor.callCount())
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+)
+
+const drainNotifyTimeout = 5 * time.Second
+
+// DrainNotifyConfig selects how the application is told that a drain has
+// started, so it can stop keep-alives instead of waiting them out. Any
+// combination of channels may be set.
+type DrainNotifyConfig struct {
+	Signal   os.Signal
+	URL      string
+	FilePath string
+}
+
+func (c *Coordinator) notifyDrain(ctx context.Context) {
+	notify := c.config.DrainNotify
+
+	if notify.Signal != nil {
+		recordDrainNotify("signal", c.signalDrain(notify.Signal))
+	}
+
+	if notify.URL != "" {
+		recordDrainNotify("http", postDrain(ctx, notify.URL))
+	}
+
+	if notify.FilePath != "" {
+		recordDrainNotify("file", os.WriteFile(notify.FilePath, nil, 0o644))
+	}
+}
+
+func (c *Coordinator) signalDrain(signal os.Signal) error {
+	if c.appProcess == nil {
+		return fmt.Errorf("no application process")
+	}
+
+	if err := c.appProcess.Signal(signal); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	run := c.current
+	c.mu.Unlock()
+	if run != nil {
+		run.timeline.signalSent(signal, c.appProcess.Pid)
+	}
+	return nil
+}
+
+// clearDrainNotify removes the drain marker file after a cancelled drain.
+// Signals and HTTP notifications cannot be taken back.
+func (c *Coordinator) clearDrainNotify() {
+	path := c.config.DrainNotify.FilePath
+	if path == "" {
+		return
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		slog.Warn("Failed to remove drain notify file", "path", path, "error", err)
+	}
+}
+
+func postDrain(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, drainNotifyTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	isSuccess := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !isSuccess {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func recordDrainNotify(channel string, err error) {
+	if err != nil {
+		metrics.DrainNotifications.WithLabelValues(channel, "failed").Inc()
+		slog.Warn("Failed to notify application of drain", "channel", channel, "error", err)
+		return
+	}
+
+	metrics.DrainNotifications.WithLabelValues(channel, "success").Inc()
+	slog.Info("Notified application of drain", "channel", channel)
+}
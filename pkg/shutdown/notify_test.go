@@ -0,0 +1,136 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/metrics"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func drainNotificationCount(channel string, result string) float64 {
+	metric := &dto.Metric{}
+	metrics.DrainNotifications.WithLabelValues(channel, result).Write(metric)
+	return metric.Counter.GetValue()
+}
+
+func TestCoordinator_InitiateShutdown_DrainNotifySignal(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "notified")
+
+	script := `trap 'touch "$0"' USR1; trap 'exit 0' TERM; while :; do sleep 0.05; done`
+	cmd := exec.Command("sh", "-c", script, marker)
+	err := cmd.Start()
+	assert.NoError(t, err)
+	defer cmd.Process.Kill()
+
+	time.Sleep(100 * time.Millisecond)
+
+	cfg := &ShutdownConfig{
+		DrainTimeout:    time.Second,
+		ShutdownTimeout: 5 * time.Second,
+		SignalToApp:     "SIGTERM",
+		DrainNotify:     DrainNotifyConfig{Signal: syscall.SIGUSR1},
+	}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, cmd.Process)
+
+	err = coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err)
+
+	_, statErr := os.Stat(marker)
+	assert.NoError(t, statErr, "the application should receive the drain signal before it is stopped")
+}
+
+func TestCoordinator_InitiateShutdown_DrainNotifyHTTP(t *testing.T) {
+	var method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+	}))
+	defer server.Close()
+
+	before := drainNotificationCount("http", "success")
+
+	cfg := &ShutdownConfig{
+		DrainTimeout: time.Second,
+		DrainNotify:  DrainNotifyConfig{URL: server.URL + "/drain"},
+	}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
+
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, before+1, drainNotificationCount("http", "success"))
+}
+
+func TestCoordinator_InitiateShutdown_DrainNotifyHTTPFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	before := drainNotificationCount("http", "failed")
+
+	cfg := &ShutdownConfig{
+		DrainTimeout: time.Second,
+		DrainNotify:  DrainNotifyConfig{URL: server.URL + "/drain"},
+	}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
+
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err, "a failed notification must not stop the shutdown")
+
+	assert.Equal(t, before+1, drainNotificationCount("http", "failed"))
+}
+
+func TestCoordinator_InitiateShutdown_DrainNotifyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "draining")
+
+	cfg := &ShutdownConfig{
+		DrainTimeout: time.Second,
+		DrainNotify:  DrainNotifyConfig{FilePath: path},
+	}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
+
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err)
+
+	_, statErr := os.Stat(path)
+	assert.NoError(t, statErr)
+}
+
+func TestCoordinator_InitiateShutdown_DrainNotifySignalWithoutProcess(t *testing.T) {
+	before := drainNotificationCount("signal", "failed")
+
+	cfg := &ShutdownConfig{
+		DrainTimeout: time.Second,
+		DrainNotify:  DrainNotifyConfig{Signal: syscall.SIGUSR1},
+	}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
+
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err)
+
+	assert.Equal(t, before+1, drainNotificationCount("signal", "failed"))
+}
@@ -0,0 +1,297 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultTerminationLogPath is where Kubernetes reads a container's
+// termination message from.
+const DefaultTerminationLogPath = "/dev/termination-log"
+
+// terminationLogLimit is the most Kubernetes keeps of a termination message.
+const terminationLogLimit = 4096
+
+var timelineSampleInterval = time.Second
+
+// TimelineConfig controls the report written when a shutdown finishes.
+type TimelineConfig struct {
+	Enabled            bool
+	TerminationLogPath string
+}
+
+// ConnectionCounter is implemented by connection monitors that can report
+// the current count, which the timeline samples during the shutdown.
+type ConnectionCounter interface {
+	CountActiveConnections() (int, error)
+}
+
+// Timeline records what happened during one shutdown, so a slow or forced
+// stop can be explained after the container is gone.
+type Timeline struct {
+	mu sync.Mutex
+
+	Trigger     string             `json:"trigger"`
+	Signal      string             `json:"signal,omitempty"`
+	Start       time.Time          `json:"start"`
+	End         time.Time          `json:"end"`
+	DurationMS  int64              `json:"duration_ms"`
+	Result      string             `json:"result"`
+	Error       string             `json:"error,omitempty"`
+	Phases      []TimelinePhase    `json:"phases"`
+	Connections []ConnectionSample `json:"connections,omitempty"`
+	SignalsSent []SentSignal       `json:"signals_sent,omitempty"`
+	ExitCode    *int               `json:"exit_code,omitempty"`
+	ExitSignal  string             `json:"exit_signal,omitempty"`
+	ForceKilled bool               `json:"force_killed"`
+}
+
+type TimelinePhase struct {
+	Name       string    `json:"name"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	DurationMS int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// ConnectionSample is recorded whenever the connection count changes.
+type ConnectionSample struct {
+	Time  time.Time `json:"time"`
+	Count int       `json:"count"`
+}
+
+type SentSignal struct {
+	Time   time.Time `json:"time"`
+	Signal string    `json:"signal"`
+	PID    int       `json:"pid"`
+}
+
+// newTimeline starts a timeline for a drain started by trigger, either a
+// shutdown signal or a preStop request.
+func newTimeline(trigger string) *Timeline {
+	return &Timeline{
+		Trigger: trigger,
+		Start:   time.Now(),
+	}
+}
+
+func (t *Timeline) setSignal(sig os.Signal) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Signal = sig.String()
+}
+
+// phase starts the named phase and returns the function that ends it.
+func (t *Timeline) phase(name string) func(err error) {
+	start := time.Now()
+
+	return func(err error) {
+		end := time.Now()
+		phase := TimelinePhase{
+			Name:       name,
+			Start:      start,
+			End:        end,
+			DurationMS: end.Sub(start).Milliseconds(),
+		}
+		if err != nil {
+			phase.Error = err.Error()
+		}
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.Phases = append(t.Phases, phase)
+	}
+}
+
+func (t *Timeline) signalSent(sig os.Signal, pid int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.SignalsSent = append(t.SignalsSent, SentSignal{Time: time.Now(), Signal: sig.String(), PID: pid})
+	if sig == syscall.SIGKILL {
+		t.ForceKilled = true
+	}
+}
+
+func (t *Timeline) connections(count int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	isUnchanged := len(t.Connections) > 0 && t.Connections[len(t.Connections)-1].Count == count
+	if isUnchanged {
+		return
+	}
+	t.Connections = append(t.Connections, ConnectionSample{Time: time.Now(), Count: count})
+}
+
+func (t *Timeline) exited(state *os.ProcessState) {
+	if state == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	code := state.ExitCode()
+	t.ExitCode = &code
+
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		t.ExitSignal = status.Signal().String()
+	}
+}
+
+func (t *Timeline) finish(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.End = time.Now()
+	t.DurationMS = t.End.Sub(t.Start).Milliseconds()
+
+	switch {
+	case err == nil:
+		t.Result = "completed"
+	case errors.Is(err, ErrShutdownTimeout):
+		t.Result = "timeout"
+	case errors.Is(err, ErrDrainCancelled):
+		t.Result = "drain_cancelled"
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		t.Result = "cancelled"
+	default:
+		t.Result = "error"
+	}
+	if err != nil {
+		t.Error = err.Error()
+	}
+}
+
+// MarshalJSON encodes the timeline under its lock, since a repeated signal
+// may still be recording into it.
+func (t *Timeline) MarshalJSON() ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	type timeline Timeline
+	return json.Marshal((*timeline)(t))
+}
+
+// sampleConnections records the connection count until ctx ends.
+func (t *Timeline) sampleConnections(ctx context.Context, counter ConnectionCounter) {
+	ticker := time.NewTicker(timelineSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		if count, err := counter.CountActiveConnections(); err == nil {
+			t.connections(count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportTimeline logs the timeline as JSON and writes it to the termination
+// log, trimmed to what Kubernetes keeps.
+func (c *Coordinator) reportTimeline(timeline *Timeline) {
+	data, err := json.Marshal(timeline)
+	if err != nil {
+		slog.Error("Failed to encode shutdown timeline", "error", err)
+		return
+	}
+
+	slog.Info("Shutdown timeline", "timeline", string(data))
+
+	path := c.config.Timeline.TerminationLogPath
+	if path == "" {
+		return
+	}
+
+	if err := writeTerminationLog(path, trimTimeline(timeline, data)); err != nil {
+		slog.Warn("Skipping termination log, it is not writable", "path", path, "error", err)
+	}
+}
+
+// trimTimeline shortens data, the encoded timeline, to terminationLogLimit.
+// Kubernetes cuts longer messages off mid-JSON, so connection samples, sent
+// signals and phases are dropped in turn, and the error is cut short last.
+func trimTimeline(timeline *Timeline, data []byte) []byte {
+	drops := []func(){
+		func() { timeline.Connections = nil },
+		func() { timeline.SignalsSent = nil },
+		func() { timeline.Phases = nil },
+	}
+
+	for _, drop := range drops {
+		if len(data) <= terminationLogLimit {
+			return data
+		}
+
+		timeline.mu.Lock()
+		drop()
+		timeline.mu.Unlock()
+
+		data, _ = json.Marshal(timeline)
+	}
+
+	// Escaping can make the encoded error longer than the error itself, so
+	// cutting it once may not be enough.
+	for len(data) > terminationLogLimit && timeline.Error != "" {
+		excess := len(data) - terminationLogLimit + len("...")
+
+		timeline.mu.Lock()
+		keep := max(len(timeline.Error)-excess, 0)
+		timeline.Error = strings.ToValidUTF8(timeline.Error[:keep], "") + "..."
+		if keep == 0 {
+			timeline.Error = ""
+		}
+		timeline.mu.Unlock()
+
+		data, _ = json.Marshal(timeline)
+	}
+
+	return data
+}
+
+// writeTerminationLog writes data to path. Kubernetes creates the default
+// path in every container, so it is never created here; outside a pod that
+// would leave a stray file in /dev.
+func writeTerminationLog(path string, data []byte) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if path == DefaultTerminationLogPath {
+		flags &^= os.O_CREATE
+	}
+
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,217 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingConnectionMonitor reports a falling connection count and drains
+// once it reaches zero.
+type countingConnectionMonitor struct {
+	mu     sync.Mutex
+	counts []int
+}
+
+func (m *countingConnectionMonitor) CountActiveConnections() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := m.counts[0]
+	if len(m.counts) > 1 {
+		m.counts = m.counts[1:]
+	}
+	return count, nil
+}
+
+func (m *countingConnectionMonitor) WaitForZeroConnections(ctx context.Context, timeout time.Duration) error {
+	time.Sleep(100 * time.Millisecond)
+	return nil
+}
+
+func readTimeline(t *testing.T, path string) map[string]any {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var timeline map[string]any
+	assert.NoError(t, json.Unmarshal(data, &timeline))
+	return timeline
+}
+
+func TestCoordinator_Timeline_WritesTerminationLog(t *testing.T) {
+	original := timelineSampleInterval
+	timelineSampleInterval = 10 * time.Millisecond
+	defer func() { timelineSampleInterval = original }()
+
+	path := filepath.Join(t.TempDir(), "termination-log")
+	cfg := &ShutdownConfig{
+		DrainTimeout:    time.Second,
+		ShutdownTimeout: time.Second,
+		SignalToApp:     "SIGTERM",
+		Timeline:        TimelineConfig{Enabled: true, TerminationLogPath: path},
+	}
+
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+
+	connMonitor := &countingConnectionMonitor{counts: []int{3, 3, 1, 0}}
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, connMonitor, cmd.Process)
+
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err)
+
+	timeline := readTimeline(t, path)
+	assert.Equal(t, "signal", timeline["trigger"])
+	assert.Equal(t, "terminated", timeline["signal"])
+	assert.Equal(t, "completed", timeline["result"])
+	assert.Equal(t, false, timeline["force_killed"])
+	assert.Equal(t, float64(-1), timeline["exit_code"])
+	assert.Equal(t, "terminated", timeline["exit_signal"])
+
+	var phases []string
+	for _, phase := range timeline["phases"].([]any) {
+		phases = append(phases, phase.(map[string]any)["name"].(string))
+	}
+	assert.Equal(t, []string{"connection_drain", "app_exit"}, phases)
+
+	var counts []float64
+	for _, sample := range timeline["connections"].([]any) {
+		counts = append(counts, sample.(map[string]any)["count"].(float64))
+	}
+	assert.Equal(t, []float64{3, 1, 0}, counts, "only changes in the count are recorded")
+
+	signals := timeline["signals_sent"].([]any)
+	assert.Len(t, signals, 1)
+	assert.Equal(t, "terminated", signals[0].(map[string]any)["signal"])
+}
+
+func TestCoordinator_Timeline_RecordsForceKill(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "termination-log")
+	cfg := &ShutdownConfig{
+		DrainTimeout:          100 * time.Millisecond,
+		ShutdownTimeout:       200 * time.Millisecond,
+		SignalToApp:           "SIGTERM",
+		ForceKillAfterTimeout: true,
+		Timeline:              TimelineConfig{Enabled: true, TerminationLogPath: path},
+	}
+
+	cmd := exec.Command("/bin/sh", "testdata/ignore_sigterm.sh")
+	assert.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	time.Sleep(50 * time.Millisecond)
+
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, cmd.Process)
+
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.Equal(t, ErrShutdownTimeout, err)
+
+	timeline := readTimeline(t, path)
+	assert.Equal(t, "timeout", timeline["result"])
+	assert.Equal(t, true, timeline["force_killed"])
+	assert.Len(t, timeline["signals_sent"], 2)
+}
+
+func TestCoordinator_Timeline_Disabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "termination-log")
+	cfg := &ShutdownConfig{
+		DrainTimeout: 100 * time.Millisecond,
+		Timeline:     TimelineConfig{Enabled: false, TerminationLogPath: path},
+	}
+
+	coordinator := NewCoordinator(cfg, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
+	err := coordinator.InitiateShutdown(context.Background(), syscall.SIGTERM)
+	assert.NoError(t, err)
+
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestCoordinator_ReportTimeline_FitsTerminationLogLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "termination-log")
+	coordinator := NewCoordinator(&ShutdownConfig{
+		Timeline: TimelineConfig{Enabled: true, TerminationLogPath: path},
+	}, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
+
+	timeline := newTimeline("signal")
+	for i := 500; i > 0; i-- {
+		timeline.connections(i)
+	}
+	timeline.finish(nil)
+
+	coordinator.reportTimeline(timeline)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(data), terminationLogLimit)
+	assert.NotContains(t, string(data), `"connections"`)
+}
+
+func TestCoordinator_ReportTimeline_SkipsUnwritableTerminationLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "termination-log")
+	coordinator := NewCoordinator(&ShutdownConfig{
+		Timeline: TimelineConfig{Enabled: true, TerminationLogPath: path},
+	}, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
+
+	timeline := newTimeline("signal")
+	timeline.finish(nil)
+
+	coordinator.reportTimeline(timeline)
+
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCoordinator_ReportTimeline_TrimsPhasesAndSignals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "termination-log")
+	coordinator := NewCoordinator(&ShutdownConfig{
+		Timeline: TimelineConfig{Enabled: true, TerminationLogPath: path},
+	}, &mockHealthServer{}, &mockConnectionMonitor{}, nil)
+
+	timeline := newTimeline("signal")
+	for i := 0; i < 100; i++ {
+		timeline.phase(fmt.Sprintf("pre_stop_hook_%d", i))(errors.New("hook failed"))
+		timeline.signalSent(syscall.SIGTERM, 1000+i)
+	}
+	timeline.finish(errors.New(strings.Repeat(`"<x>"`, terminationLogLimit)))
+
+	coordinator.reportTimeline(timeline)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(data), terminationLogLimit)
+	assert.True(t, json.Valid(data), "the termination log must stay valid JSON")
+
+	var report map[string]any
+	assert.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, "error", report["result"])
+	assert.Nil(t, report["phases"])
+	assert.NotContains(t, report, "signals_sent")
+}
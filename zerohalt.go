@@ -0,0 +1,378 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package zerohalt runs zerohalt's drain logic inside a Go service, for
+// programs that want graceful shutdown without a separate PID 1 wrapper.
+//
+// Run serves the health endpoint, watches connections on the service's
+// ports and, on a shutdown signal, fails the health check, waits for the
+// connections to drain and then calls the service's stop functions:
+//
+//	srv := &http.Server{Addr: ":8080", Handler: mux}
+//	go srv.ListenAndServe()
+//
+//	err := zerohalt.Run(ctx,
+//		zerohalt.WithPorts(8080),
+//		zerohalt.WithHTTPServer(srv),
+//	)
+package zerohalt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/config"
+	"github.com/jpasei/zerohalt/pkg/health"
+	"github.com/jpasei/zerohalt/pkg/monitor"
+	"github.com/jpasei/zerohalt/pkg/shutdown"
+)
+
+// StopFunc stops part of the service. ctx expires when the shutdown timeout,
+// or the shutdown budget, runs out.
+type StopFunc func(ctx context.Context) error
+
+// HTTPServer is the part of *http.Server that Run needs to stop it.
+type HTTPServer interface {
+	SetKeepAlivesEnabled(enabled bool)
+	Shutdown(ctx context.Context) error
+}
+
+type Option func(*options)
+
+type options struct {
+	healthPort      uint16
+	healthPath      string
+	metricsPath     string
+	ports           []uint16
+	checkInterval   time.Duration
+	steadyStateWait time.Duration
+	signals         []os.Signal
+	shutdown        shutdown.ShutdownConfig
+	linger          time.Duration
+	drainListeners  []func()
+	stopFuncs       []StopFunc
+}
+
+func defaultOptions() *options {
+	cfg := config.DefaultConfig()
+
+	return &options{
+		healthPort:      cfg.Health.Port,
+		healthPath:      cfg.Health.Path,
+		ports:           []uint16{cfg.App.Port},
+		checkInterval:   cfg.Shutdown.ConnectionCheckInterval,
+		steadyStateWait: cfg.Shutdown.DrainSteadyStateWait,
+		signals:         []os.Signal{syscall.SIGTERM, syscall.SIGINT},
+		shutdown: shutdown.ShutdownConfig{
+			DrainTimeout:       cfg.Shutdown.DrainTimeout,
+			ShutdownTimeout:    cfg.Shutdown.ShutdownTimeout,
+			RepeatSignalAction: cfg.Shutdown.RepeatSignalAction,
+		},
+	}
+}
+
+// WithHealthPort sets the port of the health endpoint. Defaults to 8888.
+func WithHealthPort(port uint16) Option {
+	return func(o *options) {
+		o.healthPort = port
+	}
+}
+
+// WithHealthPath sets the path of the health endpoint. Defaults to /health.
+func WithHealthPath(path string) Option {
+	return func(o *options) {
+		o.healthPath = path
+	}
+}
+
+// WithMetrics serves Prometheus metrics on the health port at path.
+func WithMetrics(path string) Option {
+	return func(o *options) {
+		o.metricsPath = path
+	}
+}
+
+// WithPorts sets the ports whose connections are drained. Defaults to 8080.
+func WithPorts(ports ...uint16) Option {
+	return func(o *options) {
+		o.ports = ports
+	}
+}
+
+// WithConnectionCheckInterval sets how often connections are counted.
+func WithConnectionCheckInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.checkInterval = interval
+	}
+}
+
+// WithSteadyStateWait sets how long the connection count must stay at zero
+// before the drain completes.
+func WithSteadyStateWait(wait time.Duration) Option {
+	return func(o *options) {
+		o.steadyStateWait = wait
+	}
+}
+
+// WithSignals sets the signals that start a shutdown. Defaults to SIGTERM
+// and SIGINT.
+func WithSignals(signals ...os.Signal) Option {
+	return func(o *options) {
+		o.signals = signals
+	}
+}
+
+// WithDrainTimeout bounds the drain phase: the drain delay and the wait for
+// connections to close.
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdown.DrainTimeout = timeout
+	}
+}
+
+// WithDrainDelay keeps serving for delay after the health check starts
+// failing, so load balancers can notice before connections are counted.
+func WithDrainDelay(delay time.Duration) Option {
+	return func(o *options) {
+		o.shutdown.DrainDelay = delay
+	}
+}
+
+// WithShutdownTimeout bounds the stop functions.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdown.ShutdownTimeout = timeout
+	}
+}
+
+// WithShutdownBudget replaces the drain and shutdown timeouts with one total
+// budget. Time the drain leaves unused goes to the stop functions.
+func WithShutdownBudget(budget shutdown.Budget) Option {
+	return func(o *options) {
+		o.shutdown.Budget = budget
+	}
+}
+
+// WithTimeline logs a JSON timeline of the drain when it finishes and, if
+// path is not empty, writes it there, e.g. to
+// shutdown.DefaultTerminationLogPath.
+func WithTimeline(path string) Option {
+	return func(o *options) {
+		o.shutdown.Timeline = shutdown.TimelineConfig{Enabled: true, TerminationLogPath: path}
+	}
+}
+
+// WithRepeatSignalAction sets what a second shutdown signal does. Without a
+// child process to kill, RepeatSignalKill only expedites the drain.
+func WithRepeatSignalAction(action shutdown.RepeatSignalAction) Option {
+	return func(o *options) {
+		o.shutdown.RepeatSignalAction = action
+	}
+}
+
+// WithLinger keeps the health endpoint, and metrics if enabled, serving the
+// terminating state for linger after the stop functions return.
+func WithLinger(linger time.Duration) Option {
+	return func(o *options) {
+		o.linger = linger
+	}
+}
+
+// OnDrain registers fn to run when the health check starts failing.
+func OnDrain(fn func()) Option {
+	return func(o *options) {
+		o.drainListeners = append(o.drainListeners, fn)
+	}
+}
+
+// OnStop registers fn to run once connections have drained. Stop functions
+// run in registration order.
+func OnStop(fn StopFunc) Option {
+	return func(o *options) {
+		o.stopFuncs = append(o.stopFuncs, fn)
+	}
+}
+
+// WithHTTPServer disables keep-alives on server when the drain starts and
+// shuts it down once connections have drained.
+func WithHTTPServer(server HTTPServer) Option {
+	return func(o *options) {
+		o.drainListeners = append(o.drainListeners, func() {
+			server.SetKeepAlivesEnabled(false)
+		})
+		o.stopFuncs = append(o.stopFuncs, server.Shutdown)
+	}
+}
+
+// Run reports the service healthy and blocks until a shutdown signal arrives
+// or ctx is cancelled. It then drains connections, calls the stop functions
+// and returns their errors, along with monitor.ErrDrainTimeout if
+// connections were still open when the drain gave up. Start listening before
+// calling Run; the service is reported healthy straight away.
+//
+// Cancelling ctx before a signal arrives starts a graceful shutdown bounded
+// by the configured timeouts. Cancelling it after a signal cuts the running
+// shutdown short: the drain ends, the stop functions get a cancelled context
+// and Run returns ctx's error.
+func Run(ctx context.Context, opts ...Option) error {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	healthServer := &health.DrainingServer{Server: health.NewServer(o.healthPort, o.healthPath)}
+	for _, fn := range o.drainListeners {
+		healthServer.OnDraining(fn)
+	}
+	if o.metricsPath != "" {
+		healthServer.EnableMetrics(o.metricsPath)
+	}
+	if err := healthServer.Start(); err != nil {
+		return err
+	}
+
+	connMonitor := &drainMonitor{Monitor: monitor.NewMonitor(o.ports, o.checkInterval)}
+	connMonitor.SetSteadyStateWait(o.steadyStateWait)
+	connMonitor.SetIgnoredPorts([]uint16{o.healthPort})
+	connMonitor.Start()
+	defer connMonitor.Stop()
+
+	coordinator := shutdown.NewCoordinator(&o.shutdown, healthServer, connMonitor, nil)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, o.signals...)
+	defer signal.Stop(sigChan)
+
+	healthServer.SetState(health.StateHealthy)
+	slog.Info("Zerohalt running in-process", "ports", o.ports, "health_port", o.healthPort)
+
+	var sig os.Signal
+	shutdownCtx := ctx
+	select {
+	case sig = <-sigChan:
+	case <-ctx.Done():
+		sig = syscall.SIGTERM
+		shutdownCtx = context.WithoutCancel(ctx)
+	}
+
+	return stop(shutdownCtx, coordinator, healthServer, connMonitor, sig, sigChan, o)
+}
+
+func stop(ctx context.Context, coordinator *shutdown.Coordinator, healthServer *health.DrainingServer, connMonitor *drainMonitor, sig os.Signal, sigChan chan os.Signal, o *options) error {
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- coordinator.InitiateShutdown(ctx, sig)
+	}()
+
+	var shutdownErr error
+	for drained := false; !drained; {
+		select {
+		case repeated := <-sigChan:
+			coordinator.HandleRepeatSignal(repeated)
+		case shutdownErr = <-done:
+			drained = true
+		}
+	}
+
+	healthServer.SetState(health.StateTerminating)
+
+	stopCtx, cancel := stopContext(ctx, start, o)
+	defer cancel()
+
+	var errs []error
+	if shutdownErr == nil {
+		shutdownErr = ctx.Err()
+	}
+	if shutdownErr != nil {
+		errs = append(errs, fmt.Errorf("shutdown: %w", shutdownErr))
+	}
+	if err := connMonitor.drainErr(); err != nil {
+		slog.Warn("Stopping with connections still open", "error", err)
+		errs = append(errs, fmt.Errorf("connection drain: %w", err))
+	}
+	for _, fn := range o.stopFuncs {
+		if err := fn(stopCtx); err != nil {
+			slog.Error("Stop function failed", "error", err)
+			errs = append(errs, err)
+		}
+	}
+
+	if err := stopHealthServer(healthServer, o.linger); err != nil {
+		errs = append(errs, err)
+	}
+
+	slog.Info("Zerohalt shutdown complete")
+	return errors.Join(errs...)
+}
+
+// stopHealthServer keeps reporting Terminating for linger, then shuts the
+// health server down.
+func stopHealthServer(healthServer *health.DrainingServer, linger time.Duration) error {
+	if linger > 0 {
+		slog.Info("Serving final health state before exit", "linger", linger)
+		time.Sleep(linger)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), health.ServerShutdownTimeout)
+	defer cancel()
+	return healthServer.Shutdown(ctx)
+}
+
+func stopContext(ctx context.Context, start time.Time, o *options) (context.Context, context.CancelFunc) {
+	if o.shutdown.Budget.Enabled() {
+		return context.WithDeadline(ctx, start.Add(o.shutdown.Budget.Total))
+	}
+	return context.WithTimeout(ctx, o.shutdown.ShutdownTimeout)
+}
+
+// drainMonitor remembers why the connection drain gave up, so Run can report
+// that connections were still open when the service stopped. A drain cut
+// short by a repeated signal is not a failure.
+type drainMonitor struct {
+	*monitor.Monitor
+
+	mu  sync.Mutex
+	err error
+}
+
+func (d *drainMonitor) WaitForZeroConnections(ctx context.Context, timeout time.Duration) error {
+	err := d.Monitor.WaitForZeroConnections(ctx, timeout)
+
+	isExpedited := errors.Is(ctx.Err(), context.Canceled)
+	if err != nil && !isExpedited {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = monitor.ErrDrainTimeout
+		}
+
+		d.mu.Lock()
+		d.err = err
+		d.mu.Unlock()
+	}
+
+	return err
+}
+
+func (d *drainMonitor) drainErr() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
@@ -0,0 +1,231 @@
+// Copyright 2025 JPA Solution Experts, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zerohalt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jpasei/zerohalt/pkg/monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeHTTPServer struct {
+	mu                sync.Mutex
+	keepAlivesEnabled bool
+	shutdownCalled    bool
+	shutdownErr       error
+}
+
+func (f *fakeHTTPServer) SetKeepAlivesEnabled(enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keepAlivesEnabled = enabled
+}
+
+func (f *fakeHTTPServer) Shutdown(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.shutdownCalled = true
+	return f.shutdownErr
+}
+
+func testOptions(healthPort uint16, opts ...Option) []Option {
+	base := []Option{
+		WithHealthPort(healthPort),
+		WithPorts(getAvailablePort()),
+		WithConnectionCheckInterval(20 * time.Millisecond),
+		WithSteadyStateWait(20 * time.Millisecond),
+		WithDrainTimeout(2 * time.Second),
+		WithShutdownTimeout(time.Second),
+	}
+	return append(base, opts...)
+}
+
+func waitForHealthStatus(t *testing.T, port uint16, status int) {
+	t.Helper()
+	url := fmt.Sprintf("http://localhost:%d/health", port)
+
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == status
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestRun_StopsOnContextCancel(t *testing.T) {
+	healthPort := getAvailablePort()
+	server := &fakeHTTPServer{keepAlivesEnabled: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- Run(ctx, testOptions(healthPort, WithHTTPServer(server))...)
+	}()
+
+	waitForHealthStatus(t, healthPort, http.StatusOK)
+	cancel()
+
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run should return after the drain")
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.False(t, server.keepAlivesEnabled, "keep-alives should be disabled when the drain starts")
+	assert.True(t, server.shutdownCalled)
+}
+
+func TestRun_StopsOnSignal(t *testing.T) {
+	healthPort := getAvailablePort()
+
+	var order []string
+	drain := func() { order = append(order, "drain") }
+	stop := func(ctx context.Context) error {
+		order = append(order, "stop")
+		return nil
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- Run(context.Background(), testOptions(healthPort, WithSignals(syscall.SIGUSR2), OnDrain(drain), OnStop(stop))...)
+	}()
+
+	waitForHealthStatus(t, healthPort, http.StatusOK)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run should return after the signal")
+	}
+
+	assert.Equal(t, []string{"drain", "stop"}, order)
+}
+
+func TestRun_ContextCancelAbortsShutdown(t *testing.T) {
+	healthPort := getAvailablePort()
+
+	var stopErr error
+	stop := func(ctx context.Context) error {
+		stopErr = ctx.Err()
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- Run(ctx, testOptions(healthPort, WithSignals(syscall.SIGUSR2), WithDrainDelay(time.Minute), WithDrainTimeout(2*time.Minute), OnStop(stop))...)
+	}()
+
+	waitForHealthStatus(t, healthPort, http.StatusOK)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitForHealthStatus(t, healthPort, http.StatusServiceUnavailable)
+	cancel()
+
+	select {
+	case err := <-result:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelling ctx should cut the shutdown short")
+	}
+
+	assert.ErrorIs(t, stopErr, context.Canceled, "stop functions get the cancelled context")
+}
+
+func TestRun_FailsHealthCheckWhileDraining(t *testing.T) {
+	healthPort := getAvailablePort()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- Run(ctx, testOptions(healthPort, WithDrainDelay(500*time.Millisecond))...)
+	}()
+
+	waitForHealthStatus(t, healthPort, http.StatusOK)
+	cancel()
+	waitForHealthStatus(t, healthPort, http.StatusServiceUnavailable)
+
+	<-result
+}
+
+func TestRun_ReturnsStopErrors(t *testing.T) {
+	healthPort := getAvailablePort()
+	stopErr := errors.New("stop failed")
+	server := &fakeHTTPServer{shutdownErr: stopErr}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Run(ctx, testOptions(healthPort, WithHTTPServer(server))...)
+	assert.ErrorIs(t, err, stopErr)
+}
+
+func TestRun_ReturnsDrainTimeout(t *testing.T) {
+	healthPort := getAvailablePort()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = Run(ctx, testOptions(healthPort, WithPorts(port), WithDrainTimeout(300*time.Millisecond))...)
+	assert.ErrorIs(t, err, monitor.ErrDrainTimeout)
+}
+
+func TestRun_LingersBeforeStoppingHealth(t *testing.T) {
+	healthPort := getAvailablePort()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- Run(ctx, testOptions(healthPort, WithLinger(500*time.Millisecond))...)
+	}()
+
+	waitForHealthStatus(t, healthPort, http.StatusOK)
+	cancel()
+	waitForHealthStatus(t, healthPort, http.StatusServiceUnavailable)
+
+	select {
+	case err := <-result:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run should return after the linger period")
+	}
+
+	_, err := http.Get(fmt.Sprintf("http://localhost:%d/health", healthPort))
+	assert.Error(t, err)
+}